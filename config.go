@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// config is the content of config.toml.
+type config struct {
+	DefaultProfile string             `toml:"default_profile"`
+	Profiles       map[string]profile `toml:"profiles"`
+}
+
+// profile is one WebUntis account with its display preferences.
+type profile struct {
+	Server string `toml:"server"` // host name, such as "mese.webuntis.com"
+	School string `toml:"school"`
+	URL    string `toml:"url"` // full JSON-RPC URL, overrides server and school
+	User   string `toml:"user"`
+	// PasswordCommand is run with sh -c when the password is needed, and
+	// prints it on its first line.
+	PasswordCommand string  `toml:"password_command"`
+	CacheDir        string  `toml:"cache_dir"`
+	Display         display `toml:"display"`
+}
+
+// display holds the preferences for the week grid. The zero value shows
+// everything.
+type display struct {
+	HideRooms    bool `toml:"hide_rooms"`
+	HideTeachers bool `toml:"hide_teachers"`
+	HideCodes    bool `toml:"hide_codes"`
+}
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/untistui/config.toml, or the
+// platform's equivalent.
+func defaultConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "untistui", "config.toml"), nil
+}
+
+// loadConfig reads the config file at path. A missing file is an empty
+// config unless the user asked for it explicitly.
+func loadConfig(path string, explicit bool) (config, error) {
+	var cfg config
+	meta, err := toml.DecodeFile(path, &cfg)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return config{}, nil
+	}
+	if err != nil {
+		return config{}, fmt.Errorf("reading config: %w", err)
+	}
+	for _, key := range meta.Undecoded() {
+		if key[len(key)-1] == "password" {
+			return config{}, fmt.Errorf("reading config %s: %s: passwords are not read from the config file, use password_command", path, key)
+		}
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return config{}, fmt.Errorf("reading config %s: unknown key %s", path, undecoded[0])
+	}
+	return cfg, nil
+}
+
+// profile returns the profile called name and that name. An empty name
+// picks default_profile, or the only profile there is; without either the
+// result is an empty, unnamed profile.
+func (c config) profile(name string) (profile, string, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" && len(c.Profiles) == 1 {
+		name = c.profileNames()[0]
+	}
+	if name == "" {
+		return profile{}, "", nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return profile{}, "", fmt.Errorf("no profile %q in config, have %s", name, strings.Join(c.profileNames(), ", "))
+	}
+	return p, name, nil
+}
+
+// loadProfile reads the config file, from path or the default location, and
+// returns the profile called name as profile does.
+func loadProfile(path, name string) (profile, string, error) {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = defaultConfigPath(); err != nil {
+			return profile{}, "", nil
+		}
+	}
+	cfg, err := loadConfig(path, explicit)
+	if err != nil {
+		return profile{}, "", err
+	}
+	return cfg.profile(name)
+}
+
+func (c config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rpcURL returns the JSON-RPC endpoint of the profile, or "" if it names
+// no server.
+func (p profile) rpcURL() string {
+	if p.URL != "" || p.Server == "" {
+		return p.URL
+	}
+	return "https://" + p.Server + "/WebUntis/jsonrpc.do?school=" + url.QueryEscape(p.School)
+}
+
+// firstNonEmpty returns the first of values that is not "".
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
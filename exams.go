@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	untis "UntisTui/untis"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// examWeeks is how far ahead the exams screen looks.
+const examWeeks = 8
+
+// examsMsg carries the result of fetching the exams of all exam types.
+type examsMsg struct {
+	exams []untis.Exam
+	types map[int]string
+	err   error
+}
+
+// fetchExams loads the exams of every exam type from from on in the
+// background. Exams of types that fail to load are left out.
+func fetchExams(ctx context.Context, c *untis.Client, from time.Time) tea.Cmd {
+	return func() tea.Msg {
+		to := from.AddDate(0, 0, 7*examWeeks)
+		examTypes, err := c.ExamTypes(ctx)
+		if err != nil {
+			return examsMsg{err: err}
+		}
+
+		var exams []untis.Exam
+		var errs []error
+		types := make(map[int]string)
+		for _, t := range examTypes {
+			types[t.ID] = t.LongName
+			typeExams, err := c.Exams(ctx, t.ID, from, to)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			exams = append(exams, typeExams...)
+		}
+		sort.SliceStable(exams, func(i, j int) bool {
+			return examStart(exams[i]).Before(examStart(exams[j]))
+		})
+		return examsMsg{exams, types, errors.Join(errs...)}
+	}
+}
+
+func examStart(e untis.Exam) time.Time {
+	t, _ := time.ParseInLocation(untis.DateLayout+" 15:04", e.Date+" "+e.StartTime, time.Local)
+	return t
+}
+
+// hasExam reports whether an exam of entry's classes and subject takes
+// place during entry. The exams cover the whole school, so overlapping in
+// time alone is not enough. Classes or a subject missing on either side are
+// not compared.
+func (m model) hasExam(entry untis.NamedTimetableEntry) bool {
+	for _, e := range m.exams {
+		if e.Date != entry.Date ||
+			timeToMinutes(e.StartTime) >= timeToMinutes(entry.EndTime) ||
+			timeToMinutes(entry.StartTime) >= timeToMinutes(e.EndTime) {
+			continue
+		}
+		if len(e.Classes) > 0 && len(entry.Kl) > 0 && !slices.ContainsFunc(entry.Kl, func(class string) bool {
+			return slices.Contains(e.Classes, class)
+		}) {
+			continue
+		}
+		if e.Subject != "" && len(entry.Su) > 0 && !slices.Contains(entry.Su, e.Subject) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// updateExams handles messages while the exams screen is shown.
+func (m model) updateExams(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Sequence(
+				tea.ShowCursor,
+				tea.ExitAltScreen,
+				tea.Quit,
+			)
+		case "esc", "e":
+			m.screen = screenWeek
+			return m, nil
+		}
+
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+
+	case examsMsg:
+		m.setExams(msg)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.examsView, cmd = m.examsView.Update(msg)
+	return m, cmd
+}
+
+// setExams stores fetched exams and re-renders the exams screen and the
+// week, whose exam badges depend on them.
+func (m *model) setExams(msg examsMsg) {
+	if msg.err != nil && m.screen == screenExams {
+		m.warning = "Could not load all exams: " + msg.err.Error()
+	}
+	if msg.exams == nil && msg.err != nil {
+		m.examsView.SetContent("No exam data.")
+		return
+	}
+	m.exams = msg.exams
+	m.examTypes = msg.types
+	m.examsView.SetContent(m.renderExams(m.client.Now()))
+	m.viewport.SetContent(m.renderTableContent())
+}
+
+func (m model) viewExams() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("15")).
+		Background(lipgloss.Color("12")).
+		Padding(0, 2).
+		MarginBottom(1)
+
+	footerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		MarginTop(1).
+		Italic(true)
+
+	title := titleStyle.Render("󰈙  Upcoming Exams")
+	footer := footerStyle.Render("󰌑  Press 'q' to quit  │  ↑/↓: scroll  │  esc/e: back to week")
+	if m.warning != "" {
+		warningStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")).
+			Bold(true)
+		footer = lipgloss.JoinVertical(lipgloss.Top, footer, warningStyle.Render("  "+m.warning))
+	}
+	return lipgloss.JoinVertical(lipgloss.Top, title, m.examsView.View(), footer)
+}
+
+// renderExams lays the exams from today on out as a table.
+func (m model) renderExams(now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	const maxTextLen = 16
+
+	var rows [][]string
+	var daysLeft []int
+	for _, e := range m.exams {
+		date, err := time.ParseInLocation(untis.DateLayout, e.Date, time.Local)
+		if err != nil || date.Before(today) {
+			continue
+		}
+		days := int(date.Sub(today).Hours()/24 + 0.5)
+		countdown := "in " + strconv.Itoa(days) + " days"
+		switch days {
+		case 0:
+			countdown = "today"
+		case 1:
+			countdown = "tomorrow"
+		}
+		name := m.examTypes[e.ExamTypeID]
+		if e.Name != "" {
+			name = e.Name
+		}
+		rows = append(rows, []string{
+			date.Format("Mon 02.01."),
+			e.StartTime + "–" + e.EndTime,
+			countdown,
+			truncate(e.Subject, maxTextLen),
+			truncate(name, maxTextLen),
+			truncate(strings.Join(e.Teachers, "/"), maxTextLen),
+			truncate(strings.Join(e.Rooms, "/"), maxTextLen),
+			truncate(strings.Join(e.Classes, ", "), maxTextLen),
+		})
+		daysLeft = append(daysLeft, days)
+	}
+	if len(rows) == 0 {
+		return "No exams in the next " + strconv.Itoa(examWeeks) + " weeks."
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("15")).
+		Background(lipgloss.Color("12"))
+	cellStyle := lipgloss.NewStyle().Padding(0, 1)
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("12"))).
+		Headers("Date", "Time", "Countdown", "Subject", "Exam", "Teacher", "Room", "Classes").
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerStyle
+			}
+			if col == 2 && daysLeft[row] <= 3 {
+				return cellStyle.Foreground(stateExam.color()).Bold(true)
+			}
+			return cellStyle
+		})
+	return t.Render()
+}
+
+func newExamsView() viewport.Model {
+	vp := viewport.New(80, 20)
+	vp.SetContent("Loading exams…")
+	return vp
+}
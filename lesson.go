@@ -0,0 +1,124 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	untis "UntisTui/untis"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lessonState decides how a lesson cell is styled.
+type lessonState int
+
+const (
+	stateRegular lessonState = iota
+	stateIrregular
+	stateCancelled
+	stateExam
+)
+
+// lessonStates lists every state with its fallback color and the entry of
+// the school's status data that colors it.
+var lessonStates = []struct {
+	state  lessonState
+	name   string
+	color  lipgloss.Color
+	status untis.NamedTimetableEntry
+}{
+	{stateRegular, "lesson", lipgloss.Color("10"), untis.NamedTimetableEntry{LsType: "ls"}},
+	{stateIrregular, "changed", lipgloss.Color("11"), untis.NamedTimetableEntry{Code: "irregular"}},
+	{stateCancelled, "cancelled", lipgloss.Color("240"), untis.NamedTimetableEntry{Code: "cancelled"}},
+	{stateExam, "exam", lipgloss.Color("9"), untis.NamedTimetableEntry{LsType: "ex"}},
+}
+
+// stateOf classifies entry. Besides lessons coded irregular, those that
+// replace a class, subject, room or teacher, or carry status flags, count as
+// changed, since WebUntis does not always set a code for substitutes.
+func stateOf(entry untis.NamedTimetableEntry) lessonState {
+	switch {
+	case entry.Code == "cancelled":
+		return stateCancelled
+	case entry.LsType == "ex":
+		return stateExam
+	case entry.Code == "irregular", entry.Statflags != "", substituted(entry):
+		return stateIrregular
+	}
+	return stateRegular
+}
+
+// substituted reports whether any element of entry replaces an original one.
+func substituted(entry untis.NamedTimetableEntry) bool {
+	for _, orgs := range [][]string{entry.KlOrg, entry.SuOrg, entry.RoOrg, entry.TeOrg} {
+		for _, org := range orgs {
+			if org != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s lessonState) color() lipgloss.Color {
+	for _, ls := range lessonStates {
+		if ls.state == s {
+			return ls.color
+		}
+	}
+	return lipgloss.Color("10")
+}
+
+// style derives the cell style of a lesson in state s from the base entry style.
+func (s lessonState) style(base lipgloss.Style) lipgloss.Style {
+	style := base.Foreground(s.color())
+	switch s {
+	case stateCancelled:
+		style = style.Bold(false).Strikethrough(true).BorderForeground(s.color())
+	case stateIrregular, stateExam:
+		style = style.BorderForeground(s.color())
+	}
+	return style
+}
+
+// withStatusColors applies the school's colors for entry to style, if the
+// status data has any.
+func withStatusColors(style lipgloss.Style, status untis.StatusData, entry untis.NamedTimetableEntry) lipgloss.Style {
+	lookup := entry
+	if lookup.Code == "" && stateOf(entry) == stateIrregular {
+		// substitutes without a code take the colors of irregular lessons
+		lookup.Code = "irregular"
+	}
+	colors, ok := status.Colors(lookup)
+	if !ok {
+		return style
+	}
+	if fore, ok := hexColor(colors.ForeColor); ok {
+		style = style.Foreground(fore)
+	}
+	if back, ok := hexColor(colors.BackColor); ok {
+		style = style.Background(back).BorderForeground(back)
+	}
+	return style
+}
+
+// hexColor turns a WebUntis color such as "ee7f00" into a lipgloss color.
+func hexColor(hex string) (lipgloss.Color, bool) {
+	if len(hex) != 6 {
+		return "", false
+	}
+	if _, err := strconv.ParseUint(hex, 16, 32); err != nil {
+		return "", false
+	}
+	return lipgloss.Color("#" + hex), true
+}
+
+// legend explains the lesson styles in one line for the footer.
+func legend(status untis.StatusData) string {
+	var parts []string
+	for _, ls := range lessonStates {
+		swatch := withStatusColors(lipgloss.NewStyle().Foreground(ls.color), status, ls.status)
+		parts = append(parts, swatch.Render("■")+" "+ls.name)
+	}
+	return strings.Join(parts, "  ")
+}
@@ -1,60 +1,143 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	untis "UntisTui/untis"
+	"UntisTui/untis/untistest"
 
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/joho/godotenv"
 )
 
+// screen is the view the TUI currently shows.
+type screen int
+
+const (
+	screenWeek screen = iota
+	screenPicker
+	screenSubstitutions
+	screenExams
+)
+
 type model struct {
 	days      [5][]untis.NamedTimetableEntry
 	dayNames  [5]string
 	timeSlots []string
-	timeMaps  [5]map[string]untis.NamedTimetableEntry
+	periods   []untis.Period
+	weekStart time.Time
+	holidays  []untis.Holiday
+	year      untis.Schoolyear
+	status    untis.StatusData
+	timeMaps  [5]map[string][]untis.NamedTimetableEntry
+	owner     string // name of the shown element, empty for the own timetable
+	warning   string
 	viewport  viewport.Model
+	picker    list.Model
+	screen    screen
+	client    *untis.Client
+	ctx       context.Context
+	display   display
+	offline   bool      // no server calls, everything comes from the snapshot
+	stale     bool      // the snapshot is not from a complete sync of this run
+	fetchedAt time.Time // when the shown snapshot was fetched
 	width     int
 	height    int
+
+	substitutions     []untis.Substitution
+	substitutionsView viewport.Model
+
+	exams     []untis.Exam
+	examTypes map[int]string
+	examsView viewport.Model
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(
-		tea.EnterAltScreen,
-		tea.HideCursor,
-	)
+	cmds := []tea.Cmd{tea.EnterAltScreen, tea.HideCursor}
+	if !m.offline {
+		cmds = append(cmds, fetchExams(m.ctx, m.client, m.weekStart))
+	}
+	return tea.Batch(cmds...)
 }
 
+// offlineWarning is shown when a key needs the server in offline mode.
+const offlineWarning = "Not available offline."
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	// exams are fetched at startup and feed the week badges, whatever the screen
+	if msg, ok := msg.(examsMsg); ok {
+		m.setExams(msg)
+		return m, nil
+	}
+
+	switch m.screen {
+	case screenPicker:
+		return m.updatePicker(msg)
+	case screenSubstitutions:
+		return m.updateSubstitutions(msg)
+	case screenExams:
+		return m.updateExams(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
+		key := msg.String()
+		if m.offline && (key == "p" || key == "s" || key == "e" || key == "o") {
+			m.warning = offlineWarning
+			return m, nil
+		}
+		switch key {
 		case "q", "ctrl+c", "esc":
 			return m, tea.Sequence(
 				tea.ShowCursor,
 				tea.ExitAltScreen,
 				tea.Quit,
 			)
+		case "p":
+			m.screen = screenPicker
+			return m, nil
+		case "s":
+			m.screen = screenSubstitutions
+			return m, fetchSubstitutions(m.ctx, m.client)
+		case "e":
+			m.screen = screenExams
+			if m.exams != nil {
+				m.examsView.SetContent(m.renderExams(m.client.Now()))
+			}
+			return m, fetchExams(m.ctx, m.client, m.weekStart)
+		case "o":
+			return m, fetchOwnWeek(m.ctx, m.client)
 		}
 
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		// Update viewport size and re-render content
-		m.viewport.Width = msg.Width
-		m.viewport.Height = msg.Height - 6 // account for title + footer + borders
-		content := m.renderTableContent()
-		m.viewport.SetContent(content)
+		m.resize(msg.Width, msg.Height)
+
+	case weekMsg:
+		if msg.err != nil {
+			m.warning = "Could not load timetable: " + msg.err.Error()
+			return m, nil
+		}
+		m.warning = ""
+		m.owner = msg.name
+		m.setWeek(msg.week)
+		return m, nil
 	}
 
 	// Forward messages to the viewport (essential for scrolling!)
@@ -65,9 +148,70 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// resize updates the viewport and picker sizes and re-renders the content.
+func (m *model) resize(width, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = height - 8 // account for title + footer + legend + holidays + borders
+	m.picker.SetSize(width, height)
+	m.substitutionsView.Width = width
+	m.substitutionsView.Height = height - 5 // account for title + footer + warning
+	m.examsView.Width = width
+	m.examsView.Height = height - 5
+	m.viewport.SetContent(m.renderTableContent())
+}
+
+// updatePicker handles messages while the element picker is open.
+func (m model) updatePicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Sequence(
+				tea.ShowCursor,
+				tea.ExitAltScreen,
+				tea.Quit,
+			)
+		}
+		if m.picker.FilterState() != list.Filtering {
+			switch msg.String() {
+			case "esc":
+				if m.picker.FilterState() == list.Unfiltered {
+					m.screen = screenWeek
+					return m, nil
+				}
+			case "enter":
+				m.screen = screenWeek
+				if item, ok := m.picker.SelectedItem().(elementItem); ok {
+					return m, fetchWeek(m.ctx, m.client, item.Name, item.Element)
+				}
+				return m, nil
+			}
+		}
+
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+	}
+
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
+	return m, cmd
+}
+
 func (m model) View() string {
+	switch m.screen {
+	case screenPicker:
+		return m.picker.View()
+	case screenSubstitutions:
+		return m.viewSubstitutions()
+	case screenExams:
+		return m.viewExams()
+	}
 	if len(m.timeSlots) == 0 {
-		return "📅 No timetable data.\n\n󰌑  Press q to quit"
+		if m.offline {
+			return "📅 No timetable data, " + staleNote(true, m.fetchedAt) + ".\n\n󰌑  Press q to quit"
+		}
+		return "📅 No timetable data.\n\n󰌑  Press q to quit  │  p: pick timetable"
 	}
 
 	titleStyle := lipgloss.NewStyle().
@@ -77,7 +221,17 @@ func (m model) View() string {
 		Padding(0, 2).
 		MarginBottom(1)
 
-	title := titleStyle.Render("📅  Weekly Timetable  📚")
+	heading := "📅  Weekly Timetable  📚"
+	if m.year.Name != "" {
+		heading += "  " + m.year.Name
+	}
+	if m.owner != "" {
+		heading += "  " + m.owner
+	}
+	if m.stale {
+		heading += "  " + staleNote(m.offline, m.fetchedAt)
+	}
+	title := titleStyle.Render(heading)
 
 	body := m.viewport.View()
 
@@ -86,7 +240,18 @@ func (m model) View() string {
 		MarginTop(1).
 		Italic(true)
 
-	footer := footerStyle.Render("󰌑  Press 'q' to quit  │  ↑/↓: scroll")
+	footer := footerStyle.Render("󰌑  Press 'q' to quit  │  ↑/↓: scroll  │  p: pick timetable  │  o: own timetable  │  s: substitutions  │  e: exams")
+	footer = lipgloss.JoinVertical(lipgloss.Top, footer, "  "+legend(m.status))
+	if countdown := m.holidayCountdown(m.client.Now()); countdown != "" {
+		footer = lipgloss.JoinVertical(lipgloss.Top, footer, "  "+countdown)
+	}
+
+	if m.warning != "" {
+		warningStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")).
+			Bold(true)
+		footer = lipgloss.JoinVertical(lipgloss.Top, footer, warningStyle.Render("  "+m.warning))
+	}
 
 	return lipgloss.JoinVertical(lipgloss.Top, title, body, footer)
 }
@@ -99,17 +264,18 @@ func (m model) renderTableContent() string {
 
 	// Responsive breakpoints and sizing constants
 	const (
-		largeTerminalWidth  = 140
-		mediumTerminalWidth = 100
-		largeEntryWidth     = 20
-		largeTimeWidth      = 8
-		mediumEntryWidth    = 16
-		mediumTimeWidth     = 7
-		smallEntryWidth     = 14
-		smallTimeWidth      = 6
-		minRoomDisplayWidth = 16
-		minCodeDisplayWidth = 16
-		minTextPadding      = 4
+		largeTerminalWidth     = 140
+		mediumTerminalWidth    = 100
+		largeEntryWidth        = 20
+		largeTimeWidth         = 8
+		mediumEntryWidth       = 16
+		mediumTimeWidth        = 7
+		smallEntryWidth        = 14
+		smallTimeWidth         = 6
+		minRoomDisplayWidth    = 16
+		minCodeDisplayWidth    = 16
+		minTeacherDisplayWidth = 16
+		minTextPadding         = 4
 	)
 
 	timeColWidth := largeTimeWidth
@@ -129,7 +295,6 @@ func (m model) renderTableContent() string {
 	accentColor := lipgloss.Color("13")
 	textColor := lipgloss.Color("15")
 	mutedColor := lipgloss.Color("240")
-	successColor := lipgloss.Color("10")
 
 	timeStrStyle := lipgloss.NewStyle().
 		Foreground(primaryColor).
@@ -153,7 +318,6 @@ func (m model) renderTableContent() string {
 		Background(primaryColor)
 
 	entryStyle := lipgloss.NewStyle().
-		Foreground(successColor).
 		Bold(true).
 		Padding(1, 1).
 		Width(entryColWidth).
@@ -161,6 +325,15 @@ func (m model) renderTableContent() string {
 		BorderForeground(accentColor).
 		Align(lipgloss.Center)
 
+	holidayStyle := lipgloss.NewStyle().
+		Foreground(textColor).
+		Background(lipgloss.Color("236")).
+		Padding(1, 1).
+		Width(entryColWidth).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(mutedColor).
+		Align(lipgloss.Center)
+
 	emptyEntryStyle := lipgloss.NewStyle().
 		Foreground(mutedColor).
 		Padding(1, 1).
@@ -184,57 +357,66 @@ func (m model) renderTableContent() string {
 	}
 	rows := []string{lipgloss.JoinHorizontal(lipgloss.Top, headers...)}
 
+	var holidayNames [5]string
+	for dayIdx := range holidayNames {
+		if h, ok := untis.HolidayOn(m.holidays, m.weekStart.AddDate(0, 0, dayIdx)); ok {
+			holidayNames[dayIdx] = h.LongName
+		}
+	}
+
 	for _, timeSlot := range m.timeSlots {
-		cells := []string{timeStyle.Render("  " + timeSlot)}
+		cells := []string{timeStyle.Render(m.slotLabel(timeSlot))}
 		for dayIdx := 0; dayIdx < 5; dayIdx++ {
-			if entry, exists := m.timeMaps[dayIdx][timeSlot]; exists {
-				subject := strings.Join(entry.Su, "/")
-				room := ""
-				if len(entry.Ro) > 0 {
-					room = entry.Ro[0]
-				}
-				code := " "
-				if entry.Code != "" {
-					code = entry.Code
-				}
-
-				var label string
-				if subject == "" {
-					label = "─"
-				} else {
-					displaySubject := subject
-					maxSubjectLen := entryColWidth - minTextPadding
-					if maxSubjectLen < 2 {
-						maxSubjectLen = 2
+			if holidayNames[dayIdx] != "" {
+				cells = append(cells, holidayStyle.Render("󰂚 "+truncate(holidayNames[dayIdx], entryColWidth-minTextPadding)))
+			} else if entries := m.timeMaps[dayIdx][timeSlot]; len(entries) > 0 {
+				// a cancelled lesson and its replacement share the slot, so
+				// every lesson gets its own box
+				var boxes []string
+				for _, entry := range entries {
+					subject := strings.Join(entry.Su, "/")
+					room, roomOrg := "", ""
+					if len(entry.Ro) > 0 {
+						room = entry.Ro[0]
+					}
+					if len(entry.RoOrg) > 0 {
+						roomOrg = entry.RoOrg[0]
+					}
+					teacher := strings.Join(entry.Te, "/")
+					teacherOrg := joinNonEmpty(entry.TeOrg, "/")
+					state := stateOf(entry)
+					if state != stateCancelled && m.hasExam(entry) {
+						state = stateExam
 					}
-					if len(displaySubject) > maxSubjectLen {
-						displaySubject = displaySubject[:maxSubjectLen-1] + "…"
+					code := " "
+					if entry.Code != "" {
+						code = entry.Code
+					} else if state == stateExam {
+						code = "exam"
 					}
-					label = "  " + displaySubject
-					if room != "" && entryColWidth >= minRoomDisplayWidth {
-						displayRoom := room
-						maxRoomLen := entryColWidth - minTextPadding
-						if maxRoomLen < 2 {
-							maxRoomLen = 2
+
+					var label string
+					if subject == "" {
+						label = "─"
+					} else {
+						maxTextLen := entryColWidth - minTextPadding
+						label = "  " + truncate(subject, maxTextLen)
+						if state == stateExam {
+							label = "󰈙 " + truncate(subject, maxTextLen)
 						}
-						if len(displayRoom) > maxRoomLen {
-							displayRoom = displayRoom[:maxRoomLen-1] + "…"
+						if room != "" && !m.display.HideRooms && entryColWidth >= minRoomDisplayWidth {
+							label += "\n 󰍉 " + substitution(roomOrg, room, maxTextLen)
 						}
-						label += "\n 󰍉 " + displayRoom
-					}
-					if code != "" && entryColWidth >= minCodeDisplayWidth {
-						displayCode := code
-						maxCodeLen := entryColWidth - minTextPadding
-						if maxCodeLen < 2 {
-							maxCodeLen = 2
+						if teacher != "" && !m.display.HideTeachers && entryColWidth >= minTeacherDisplayWidth {
+							label += "\n 󰦕 " + substitution(teacherOrg, teacher, maxTextLen)
 						}
-						if len(displayCode) > maxCodeLen {
-							displayCode = displayCode[:maxCodeLen-1] + "…"
+						if code != "" && !m.display.HideCodes && entryColWidth >= minCodeDisplayWidth {
+							label += "\n " + truncate(code, maxTextLen)
 						}
-						label += "\n " + displayCode
 					}
+					boxes = append(boxes, withStatusColors(state.style(entryStyle), m.status, entry).Render(label))
 				}
-				cells = append(cells, entryStyle.Render(label))
+				cells = append(cells, lipgloss.JoinVertical(lipgloss.Left, boxes...))
 			} else {
 				cells = append(cells, emptyEntryStyle.Render("━"))
 			}
@@ -254,81 +436,360 @@ func (m model) renderTableContent() string {
 }
 
 func main() {
-	err := godotenv.Overload(".env")
-	if err != nil {
+	// .env only fills in variables the environment does not set, and never
+	// the password, which should not lie around on disk in plain text
+	_, passwordInEnv := os.LookupEnv("UNTIS_PASSWORD")
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		log.Println("error reading .env ", err)
 	}
-	user := os.Getenv("UNTIS_USERNAME")
-	pass := os.Getenv("UNTIS_PASSWORD")
-	url := os.Getenv("UNTIS_URL")
-	untis.Main(user, pass, url)
+	if _, set := os.LookupEnv("UNTIS_PASSWORD"); set && !passwordInEnv {
+		log.Println("ignoring UNTIS_PASSWORD in .env, use password_command in the config file instead")
+		os.Unsetenv("UNTIS_PASSWORD")
+	}
+
+	configPath := flag.String("config", "", "config file (default $XDG_CONFIG_HOME/untistui/config.toml)")
+	profileName := flag.String("profile", "", "profile from the config file (default $UNTIS_PROFILE, then default_profile)")
+	urlFlag := flag.String("url", "", "WebUntis JSON-RPC URL (default $UNTIS_URL, then the profile's server and school)")
+	userFlag := flag.String("user", "", "WebUntis user name (default $UNTIS_USERNAME, then the profile's user)")
+	cacheDir := flag.String("cache-dir", "", "directory for cached WebUntis data (default $UNTIS_CACHE_DIR, then the profile's cache_dir, then $XDG_CACHE_HOME/untistui)")
+	offline := flag.Bool("offline", false, "show the last synced data without contacting the server")
+	demo := flag.Bool("demo", false, "show a made-up school from a local fake server, no account needed")
+	var httpConfig untis.HTTPConfig
+	flag.DurationVar(&httpConfig.Timeout, "timeout", untis.DefaultTimeout, "time limit for each request to the server, 0 for none")
+	flag.StringVar(&httpConfig.Proxy, "proxy", "", "proxy URL (default $HTTPS_PROXY)")
+	flag.StringVar(&httpConfig.CAFile, "ca-file", "", "PEM file with additional CA certificates to trust")
+	flag.IntVar(&httpConfig.MaxRetries, "retries", 3, "how often to retry a request that failed transiently")
+	flag.Float64Var(&httpConfig.RequestsPerSecond, "rps", 5, "maximum requests per second, 0 for no limit")
+	flag.StringVar(&httpConfig.RecordDir, "record", "", "save all server traffic, with credentials redacted, to this directory")
+	flag.StringVar(&httpConfig.ReplayDir, "replay", "", "answer all requests from traffic saved with --record in this directory")
+	flag.Parse()
+
+	// flags win over the environment, which wins over the config file
+	prof, profName, err := loadProfile(*configPath, firstNonEmpty(*profileName, os.Getenv("UNTIS_PROFILE")))
+	if err != nil {
+		log.Fatal(err)
+	}
+	url := firstNonEmpty(*urlFlag, os.Getenv("UNTIS_URL"), prof.rpcURL())
+	user := firstNonEmpty(*userFlag, os.Getenv("UNTIS_USERNAME"), prof.User)
+	if *demo {
+		fixtures := untistest.DemoFixtures(time.Now())
+		srv := untistest.NewServer(fixtures)
+		defer srv.Close()
+		url, user = srv.URL, fixtures.User
+	}
 
-	p := tea.NewProgram(newModel())
-	if _, err := p.Run(); err != nil {
+	store, err := openStore(firstNonEmpty(*cacheDir, os.Getenv("UNTIS_CACHE_DIR"), prof.CacheDir), profName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := untis.NewClient(url, httpConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// cancelled on ctrl+c during the startup sync and when the TUI quits, which
+	// aborts every request still in flight
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// replayed and demo data must not overwrite the cache of real syncs
+	if httpConfig.ReplayDir != "" || *demo {
+		store = nil
+	}
+
+	var snap *untis.Snapshot
+	var syncErr error
+	syncStart := client.Now()
+	if !*offline {
+		// replayed traffic answers any password, so do not ask for one
+		var pass string
+		if *demo {
+			pass = "demo"
+		} else if httpConfig.ReplayDir == "" {
+			if pass, err = password(prof, user); err != nil {
+				log.Fatal(err)
+			}
+		}
+		// a recording must hold a full sync, so it may not start from the cache
+		syncStore := store
+		if httpConfig.RecordDir != "" {
+			syncStore = nil
+		}
+		snap, syncErr = untis.Main(ctx, client, syncStore, user, pass)
+		if syncStore == nil && store != nil && snap != nil && !snap.FetchedAt.Before(syncStart) {
+			if err := store.SaveSnapshot(snap); err != nil {
+				log.Println("error writing snapshot: ", err)
+			}
+		}
+		// only a server that could not be reached for the login means offline;
+		// a sync that lost the connection halfway keeps what it fetched
+		if snap == nil && errors.Is(syncErr, untis.ErrUnreachable) {
+			log.Println("server unreachable, going offline: ", syncErr)
+			*offline, snap, syncErr = true, nil, nil
+		} else if syncErr != nil {
+			log.Println("error syncing timetable: ", syncErr)
+		}
+		if ctx.Err() != nil {
+			log.Println("interrupted")
+			logout(client)
+			return
+		}
+	}
+	if snap == nil {
+		if store == nil {
+			snap = &untis.Snapshot{}
+		} else if snap, err = store.LoadSnapshot(); err != nil {
+			snap = &untis.Snapshot{}
+		}
+		client.SetMasterData(snap.MasterData)
+	}
+
+	m := newModel(snap)
+	m.client = client
+	m.ctx = ctx
+	m.offline = *offline
+	// a complete sync, or a check that nothing changed, sets FetchedAt; any
+	// older snapshot comes from an earlier run or a failed or partial sync
+	m.stale = snap.FetchedAt.Before(syncStart)
+	m.display = prof.Display
+	if syncErr != nil {
+		m.warning = syncWarning(syncErr)
+	}
+
+	// the untis package logs from background commands, which would write over
+	// the alt screen
+	restoreLog := redirectLog(store)
+	p := tea.NewProgram(m)
+	_, err = p.Run()
+	restoreLog()
+	if err != nil {
 		panic(err)
 	}
+	cancel()
+	logout(client)
 }
 
-func loadJSON(path string) []untis.NamedTimetableEntry {
-	data, err := os.ReadFile(path)
+// redirectLog sends the log to debug.log in the store, or discards it
+// without one, and returns a func that restores logging to stderr.
+func redirectLog(store *untis.Store) func() {
+	restore := func() { log.SetOutput(os.Stderr) }
+	if store == nil {
+		log.SetOutput(io.Discard)
+		return restore
+	}
+	f, err := tea.LogToFile(store.Path("debug.log"), "")
 	if err != nil {
-		return nil
+		log.SetOutput(io.Discard)
+		return restore
 	}
-	var entries []untis.NamedTimetableEntry
-	if err := json.Unmarshal(data, &entries); err != nil {
-		return nil
+	return func() {
+		restore()
+		f.Close()
 	}
-	return entries
 }
 
-func newModel() model {
-	mon := loadJSON("timetableFilled_Monday.json")
-	tue := loadJSON("timetableFilled_Tuesday.json")
-	wed := loadJSON("timetableFilled_Wednesday.json")
-	thu := loadJSON("timetableFilled_Thursday.json")
-	fri := loadJSON("timetableFilled_Friday.json")
+// logout ends the session with its own short context, as the main one may
+// already be cancelled. Without a request limit it waits DefaultTimeout, so a
+// hung server cannot keep the app from exiting.
+func logout(client *untis.Client) {
+	timeout := client.Timeout
+	if timeout <= 0 {
+		timeout = untis.DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := client.Logout(ctx); err != nil {
+		log.Println("error logging out: ", err)
+	}
+}
 
-	days := [5][]untis.NamedTimetableEntry{mon, tue, wed, thu, fri}
-	dayNames := [5]string{"Mon", "Tue", "Wed", "Thu", "Fri"}
-	var allTimes []string
-	for _, dayEntries := range days {
-		for _, e := range dayEntries {
-			allTimes = append(allTimes, e.StartTime)
+// openStore opens the cache directory dir. Without one it uses
+// untis.DefaultStoreDir, with a subdirectory per profile so profiles do not
+// overwrite each other's snapshot.
+func openStore(dir, profileName string) (*untis.Store, error) {
+	if dir == "" {
+		defaultDir, err := untis.DefaultStoreDir()
+		if err != nil {
+			return nil, err
 		}
+		dir = filepath.Join(defaultDir, profileName)
 	}
-	timeSlots := sortTimeStrings(allTimes)
-	var timeMaps [5]map[string]untis.NamedTimetableEntry
-	for i, entries := range days {
-		timeMaps[i] = buildTimeMap(entries)
+	return untis.NewStore(dir)
+}
+
+// staleNote tells how old the shown snapshot is.
+func staleNote(offline bool, fetchedAt time.Time) string {
+	note := "data from " + fetchedAt.Format("02.01.2006 15:04")
+	if fetchedAt.IsZero() {
+		note = "no cached data"
+	}
+	if offline {
+		return "offline – " + note
+	}
+	return note
+}
+
+// syncWarning turns a sync error into a short hint for the footer.
+func syncWarning(err error) string {
+	switch {
+	case errors.Is(err, untis.ErrBadCredentials):
+		return "Login rejected, check the user name and password. Showing cached data."
+	case errors.Is(err, untis.ErrSessionExpired):
+		return "Session expired during sync. Showing cached data."
+	case errors.Is(err, untis.ErrUnreachable):
+		return "Lost the connection during sync. Some data is from the cache."
+	case errors.Is(err, untis.ErrNoRight):
+		return "Some data is not visible to this account."
+	case errors.Is(err, untis.ErrNotRecorded):
+		return "Some data is not in the recording."
+	case errors.Is(err, untis.ErrOutsideSchoolyear):
+		return "This week is outside the school year."
+	default:
+		return "Sync failed, showing cached data."
 	}
+}
 
-	// Initialize viewport with fallback size
-	vp := viewport.New(80, 20)
-	content := renderInitialTable(days, dayNames, timeSlots, timeMaps)
-	vp.SetContent(content)
+func newModel(snap *untis.Snapshot) model {
+	dayNames := [5]string{"Mon", "Tue", "Wed", "Thu", "Fri"}
+	timeSlots, timeMaps := buildWeek(snap.Week, snap.Timegrid)
+	weekStart, err := time.ParseInLocation(untis.DateLayout, snap.WeekStart, time.Local)
+	if err != nil {
+		weekStart = untis.Monday(time.Now())
+	}
 
-	return model{
-		days:      days,
+	m := model{
+		days:      snap.Week,
 		dayNames:  dayNames,
 		timeSlots: timeSlots,
+		periods:   snap.Timegrid,
+		weekStart: weekStart,
+		holidays:  snap.Holidays,
+		year:      snap.Schoolyear,
+		fetchedAt: snap.FetchedAt,
+		status:    snap.StatusData,
 		timeMaps:  timeMaps,
-		viewport:  vp,
+		viewport:  viewport.New(80, 20), // fallback size until WindowSizeMsg arrives
+		picker:    newPicker(snap.Elements()),
 		width:     80,
 		height:    24,
+
+		substitutionsView: newSubstitutionsView(),
+		examsView:         newExamsView(),
 	}
+
+	// Render the initial table at a reasonable default width
+	initial := m
+	initial.width = 120
+	m.viewport.SetContent(initial.renderTableContent())
+	return m
 }
 
-// Helper to render initial table before WindowSizeMsg arrives
-func renderInitialTable(days [5][]untis.NamedTimetableEntry, dayNames [5]string, timeSlots []string, timeMaps [5]map[string]untis.NamedTimetableEntry) string {
-	// Create a temporary model-like struct to reuse render logic
-	tempModel := model{
-		days:      days,
-		dayNames:  dayNames,
-		timeSlots: timeSlots,
-		timeMaps:  timeMaps,
-		width:     120, // reasonable default for initial render
+// holidayCountdown describes the current or next holiday for the footer.
+func (m model) holidayCountdown(now time.Time) string {
+	if h, ok := untis.HolidayOn(m.holidays, now); ok {
+		return "󰂚  " + h.LongName + " until " + h.End().Format("02.01.")
+	}
+	h, ok := untis.NextHoliday(m.holidays, now)
+	if !ok {
+		return ""
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	days := int(h.Start().Sub(today).Hours()/24 + 0.5)
+	if days == 1 {
+		return "󰂚  " + h.LongName + " starts tomorrow"
+	}
+	return "󰂚  " + h.LongName + " in " + strconv.Itoa(days) + " days"
+}
+
+// setWeek replaces the shown week and re-renders the table.
+func (m *model) setWeek(days [5][]untis.NamedTimetableEntry) {
+	m.days = days
+	m.timeSlots, m.timeMaps = buildWeek(days, m.periods)
+	m.viewport.SetContent(m.renderTableContent())
+	m.viewport.GotoTop()
+}
+
+// buildWeek returns the table rows and, per day, the lessons of each row,
+// cancelled ones last. With a time grid every period is a row, even if no lesson falls into it,
+// and a lesson fills every period it overlaps. Lessons outside the grid, or
+// all lessons without one, get a row at their own start time.
+func buildWeek(days [5][]untis.NamedTimetableEntry, periods []untis.Period) ([]string, [5]map[string][]untis.NamedTimetableEntry) {
+	var allTimes []string
+	for _, p := range periods {
+		allTimes = append(allTimes, p.StartTime)
+	}
+	var timeMaps [5]map[string][]untis.NamedTimetableEntry
+	for i, entries := range days {
+		timeMaps[i] = make(map[string][]untis.NamedTimetableEntry)
+		for _, e := range entries {
+			placed := false
+			for _, p := range periods {
+				if timeToMinutes(p.StartTime) < timeToMinutes(e.EndTime) && timeToMinutes(e.StartTime) < timeToMinutes(p.EndTime) {
+					timeMaps[i][p.StartTime] = append(timeMaps[i][p.StartTime], e)
+					placed = true
+				}
+			}
+			if !placed {
+				timeMaps[i][e.StartTime] = append(timeMaps[i][e.StartTime], e)
+				allTimes = append(allTimes, e.StartTime)
+			}
+		}
+		for _, slot := range timeMaps[i] {
+			sort.SliceStable(slot, func(a, b int) bool {
+				return stateOf(slot[a]) != stateCancelled && stateOf(slot[b]) == stateCancelled
+			})
+		}
+	}
+	return sortTimeStrings(allTimes), timeMaps
+}
+
+// slotLabel is the time column text of a row: the period name with start
+// and end time, or just the start time for rows outside the grid.
+func (m model) slotLabel(timeSlot string) string {
+	for _, p := range m.periods {
+		if p.StartTime == timeSlot {
+			return "  " + p.Name + "\n  " + p.StartTime + "\n  " + p.EndTime
+		}
+	}
+	return "  " + timeSlot
+}
+
+// substitution renders a replaced element as "~~org~~ → current", with the
+// original struck through. Without org it is just the truncated current.
+func substitution(org, current string, max int) string {
+	if org == "" {
+		return truncate(current, max)
+	}
+	half := (max - 2) / 2
+	orgStyle := lipgloss.NewStyle().
+		Strikethrough(true).
+		Foreground(lipgloss.Color("240"))
+	currentStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("11"))
+	return orgStyle.Render(truncate(org, half)) + currentStyle.Render(" → "+truncate(current, half))
+}
+
+func joinNonEmpty(parts []string, sep string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
+
+// truncate shortens s to at most max runes, ending in an ellipsis if cut.
+func truncate(s string, max int) string {
+	if max < 2 {
+		max = 2
+	}
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
 	}
-	return tempModel.renderTableContent()
+	return string(runes[:max-1]) + "…"
 }
 
 func timeToMinutes(t string) int {
@@ -352,11 +813,3 @@ func sortTimeStrings(times []string) []string {
 	})
 	return unique
 }
-
-func buildTimeMap(entries []untis.NamedTimetableEntry) map[string]untis.NamedTimetableEntry {
-	m := make(map[string]untis.NamedTimetableEntry)
-	for _, e := range entries {
-		m[e.StartTime] = e
-	}
-	return m
-}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// password returns the WebUntis password of user. It is taken from the
+// output of the profile's password_command, $UNTIS_PASSWORD or, failing
+// both, a masked prompt. The password is only kept in memory.
+func password(prof profile, user string) (string, error) {
+	if prof.PasswordCommand != "" {
+		return runPasswordCommand(prof.PasswordCommand)
+	}
+	if pass := os.Getenv("UNTIS_PASSWORD"); pass != "" {
+		return pass, nil
+	}
+	return promptPassword(user)
+}
+
+// runPasswordCommand runs command with the shell and returns the first line
+// it prints, as password managers like pass put the password there. The
+// command may ask for a passphrase on the terminal.
+func runPasswordCommand(command string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("running password_command: %w", err)
+	}
+	line, _, _ := bytes.Cut(out, []byte("\n"))
+	pass := strings.TrimSuffix(string(line), "\r")
+	if pass == "" {
+		return "", errors.New("password_command printed no password")
+	}
+	return pass, nil
+}
+
+// promptPassword asks for the password on the terminal without echoing it.
+func promptPassword(user string) (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", errors.New("no password configured and stdin is not a terminal to ask for one")
+	}
+	fmt.Fprintf(os.Stderr, "WebUntis password for %s: ", user)
+	pass, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return string(pass), nil
+}
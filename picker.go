@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+
+	untis "UntisTui/untis"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// elementItem shows a room, class, teacher or subject in the picker list.
+type elementItem struct {
+	untis.NamedElement
+}
+
+func (i elementItem) Title() string { return i.Name }
+
+func (i elementItem) Description() string {
+	if i.LongName == "" || i.LongName == i.Name {
+		return i.Type.String()
+	}
+	return i.Type.String() + " · " + i.LongName
+}
+
+func (i elementItem) FilterValue() string { return i.Name + " " + i.LongName }
+
+// weekMsg carries the result of fetching another element's week.
+type weekMsg struct {
+	name string
+	week [5][]untis.NamedTimetableEntry
+	err  error
+}
+
+// newPicker lists elements for the user to choose from.
+func newPicker(elements []untis.NamedElement) list.Model {
+	items := make([]list.Item, 0, len(elements))
+	for _, element := range elements {
+		items = append(items, elementItem{element})
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), 80, 20)
+	l.Title = "Open timetable"
+	return l
+}
+
+// fetchWeek loads the current week of element in the background.
+func fetchWeek(ctx context.Context, c *untis.Client, name string, element untis.Element) tea.Cmd {
+	return func() tea.Msg {
+		week, err := c.Week(ctx, c.Now(), element)
+		return weekMsg{name, week, err}
+	}
+}
+
+// fetchOwnWeek loads the current week of the logged-in user.
+func fetchOwnWeek(ctx context.Context, c *untis.Client) tea.Cmd {
+	return func() tea.Msg {
+		element, err := c.PersonElement()
+		if err != nil {
+			return weekMsg{err: err}
+		}
+		week, err := c.Week(ctx, c.Now(), element)
+		return weekMsg{"", week, err}
+	}
+}
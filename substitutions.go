@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	untis "UntisTui/untis"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// substitutionDays is how far ahead the substitution plan looks.
+const substitutionDays = 7
+
+// substitutionsMsg carries the result of fetching the substitution plan.
+type substitutionsMsg struct {
+	substitutions []untis.Substitution
+	err           error
+}
+
+// fetchSubstitutions loads the substitution plan of all departments from
+// today on in the background.
+func fetchSubstitutions(ctx context.Context, c *untis.Client) tea.Cmd {
+	return func() tea.Msg {
+		from := c.Now()
+		to := from.AddDate(0, 0, substitutionDays)
+		substitutions, err := c.Substitutions(ctx, from, to, 0)
+		return substitutionsMsg{substitutions, err}
+	}
+}
+
+// updateSubstitutions handles messages while the substitution plan is shown.
+func (m model) updateSubstitutions(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Sequence(
+				tea.ShowCursor,
+				tea.ExitAltScreen,
+				tea.Quit,
+			)
+		case "esc", "s":
+			m.screen = screenWeek
+			return m, nil
+		}
+
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+
+	case substitutionsMsg:
+		if msg.err != nil {
+			m.warning = "Could not load substitutions: " + msg.err.Error()
+			m.substitutionsView.SetContent("No substitution data.")
+			return m, nil
+		}
+		m.warning = ""
+		m.substitutions = msg.substitutions
+		m.substitutionsView.SetContent(m.renderSubstitutions())
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.substitutionsView, cmd = m.substitutionsView.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewSubstitutions() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("15")).
+		Background(lipgloss.Color("12")).
+		Padding(0, 2).
+		MarginBottom(1)
+
+	footerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		MarginTop(1).
+		Italic(true)
+
+	title := titleStyle.Render("󰓎  Substitutions")
+	footer := footerStyle.Render("󰌑  Press 'q' to quit  │  ↑/↓: scroll  │  esc/s: back to week")
+	if m.warning != "" {
+		warningStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")).
+			Bold(true)
+		footer = lipgloss.JoinVertical(lipgloss.Top, footer, warningStyle.Render("  "+m.warning))
+	}
+	return lipgloss.JoinVertical(lipgloss.Top, title, m.substitutionsView.View(), footer)
+}
+
+// renderSubstitutions lays the substitution plan out as a table.
+func (m model) renderSubstitutions() string {
+	if len(m.substitutions) == 0 {
+		return "No substitutions in the next " + strconv.Itoa(substitutionDays) + " days."
+	}
+
+	const maxTextLen = 16
+
+	typeColors := map[string]lipgloss.Color{
+		"cancel": lipgloss.Color("240"),
+		"subst":  lipgloss.Color("11"),
+		"add":    lipgloss.Color("10"),
+		"shift":  lipgloss.Color("14"),
+		"rmchg":  lipgloss.Color("13"),
+	}
+
+	var rows [][]string
+	for _, s := range m.substitutions {
+		day := s.Date
+		if date, err := time.ParseInLocation(untis.DateLayout, s.Date, time.Local); err == nil {
+			day = date.Format("Mon 02.01.")
+		}
+		rows = append(rows, []string{
+			day,
+			s.StartTime + "–" + s.EndTime,
+			s.Type,
+			truncate(strings.Join(s.Kl, ", "), maxTextLen),
+			truncate(strings.Join(s.Su, "/"), maxTextLen),
+			substitution(joinNonEmpty(s.TeOrg, "/"), strings.Join(s.Te, "/"), maxTextLen),
+			substitution(joinNonEmpty(s.RoOrg, "/"), strings.Join(s.Ro, "/"), maxTextLen),
+			s.Txt,
+		})
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(0, 1).
+		Foreground(lipgloss.Color("15")).
+		Background(lipgloss.Color("12"))
+	cellStyle := lipgloss.NewStyle().Padding(0, 1)
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("12"))).
+		Headers("Date", "Time", "Type", "Classes", "Subject", "Teacher", "Room", "Text").
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerStyle
+			}
+			if col == 2 {
+				if color, ok := typeColors[m.substitutions[row].Type]; ok {
+					return cellStyle.Foreground(color).Bold(true)
+				}
+			}
+			return cellStyle
+		})
+	return t.Render()
+}
+
+func newSubstitutionsView() viewport.Model {
+	vp := viewport.New(80, 20)
+	vp.SetContent("Loading substitutions…")
+	return vp
+}
@@ -0,0 +1,222 @@
+package untis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const requestID = "2023-05-06 15:44:22.215292"
+
+// Client is a WebUntis JSON-RPC client. It keeps the session cookies
+// returned by authenticate and sends them with every later call. After a
+// successful Auth it also remembers the credentials, so an expired session
+// is renewed transparently. Names in timetables are resolved against the
+// master data given to SetMasterData. A Client is safe for concurrent use.
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+	Timeout    time.Duration // limits each request, zero means no limit
+	MaxRetries int           // retries of transient failures per call
+
+	limiter *rateLimiter
+	offset  time.Duration // shifts Now into a replayed recording
+
+	mu          sync.Mutex // guards the fields below
+	cookies     []*http.Cookie
+	user        string
+	password    string
+	session     Loginresult
+	generation  int // counts logins, so concurrent calls renew a session once
+	names       idMaps
+	schoolyears []Schoolyear
+
+	authMu sync.Mutex // serializes session renewals
+}
+
+type rpcRequest struct {
+	ID      string `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+	Jsonrpc string `json:"jsonrpc"`
+}
+
+type rpcResponse struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	ID      string          `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+}
+
+// NewClient returns a client for the JSON-RPC endpoint url whose HTTP client
+// is built from cfg.
+func NewClient(url string, cfg HTTPConfig) (*Client, error) {
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var offset time.Duration
+	if cfg.ReplayDir != "" {
+		if offset, err = replayOffset(cfg.ReplayDir); err != nil {
+			return nil, err
+		}
+	}
+	return &Client{
+		URL:        url,
+		HTTPClient: httpClient,
+		Timeout:    cfg.Timeout,
+		MaxRetries: cfg.MaxRetries,
+		limiter:    newRateLimiter(cfg.RequestsPerSecond),
+		offset:     offset,
+	}, nil
+}
+
+// Now returns the current time. When replaying, it returns the time as if
+// the recording were running now, so weeks and date ranges computed from it
+// match the recorded requests.
+func (c *Client) Now() time.Time {
+	return time.Now().Add(c.offset)
+}
+
+// Call sends method with params to the server and decodes the result into
+// result. A nil params is sent as an empty object, a nil result discards it.
+// An error object in the response is returned as *RPCError. Transient
+// failures are retried with backoff. If the session has expired, Call logs in
+// again and retries the call once.
+func (c *Client) Call(ctx context.Context, method string, params any, result any) error {
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+
+	err := c.send(ctx, method, params, result)
+	if !errors.Is(err, ErrSessionExpired) || method == "authenticate" {
+		return err
+	}
+	if err := c.renew(ctx, method, generation); err != nil {
+		return err
+	}
+	return c.send(ctx, method, params, result)
+}
+
+// renew logs in again after a call made with the session of generation
+// failed. If another call already renewed that session, renew returns at once.
+func (c *Client) renew(ctx context.Context, method string, generation int) error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	c.mu.Lock()
+	user, password := c.user, c.password
+	renewed := c.generation != generation
+	if !renewed {
+		c.cookies = nil
+	}
+	c.mu.Unlock()
+	if renewed {
+		return nil
+	}
+	if user == "" {
+		return fmt.Errorf("%s: %w", method, ErrSessionExpired)
+	}
+
+	log.Printf("Session expired during %s, logging in again", method)
+	if err := c.Auth(ctx, user, password); err != nil {
+		return fmt.Errorf("renewing session: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	if params == nil {
+		params = map[string]any{}
+	}
+	body, err := json.Marshal(rpcRequest{requestID, method, params, "2.0"})
+	if err != nil {
+		return fmt.Errorf("marshaling %s request: %w", method, err)
+	}
+
+	reqCtx := ctx
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(reqCtx, "POST", c.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Webuntis Test")
+	c.mu.Lock()
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	c.mu.Unlock()
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		// a request that timed out counts as unreachable, one whose caller
+		// gave up or that a replay cannot answer does not
+		if ctx.Err() != nil || errors.Is(err, ErrNotRecorded) {
+			return fmt.Errorf("%s request: %w", method, err)
+		}
+		return fmt.Errorf("%s request: %w: %w", method, ErrUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%s request: %w: %s", method, ErrServer, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s request: unexpected status %s", method, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("reading %s response: %w", method, err)
+		}
+		return fmt.Errorf("reading %s response: %w: %w", method, ErrUnreachable, err)
+	}
+	var out rpcResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("unmarshaling %s response: %w", method, err)
+	}
+	if out.Error != nil {
+		return fmt.Errorf("%s: %w", method, out.Error)
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		c.mu.Lock()
+		c.cookies = cookies
+		c.mu.Unlock()
+	}
+	if result == nil || len(out.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(out.Result, result); err != nil {
+		return fmt.Errorf("unmarshaling %s result: %w", method, err)
+	}
+	return nil
+}
+
+// Logout ends the server session and forgets the stored credentials.
+func (c *Client) Logout(ctx context.Context) error {
+	c.mu.Lock()
+	loggedIn := c.cookies != nil
+	c.mu.Unlock()
+	if !loggedIn {
+		return nil
+	}
+	err := c.send(ctx, "logout", nil, nil)
+	c.mu.Lock()
+	c.cookies = nil
+	c.user, c.password = "", ""
+	c.mu.Unlock()
+	return err
+}
@@ -0,0 +1,99 @@
+package untis_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	untis "UntisTui/untis"
+	"UntisTui/untis/untistest"
+)
+
+// newClient starts a demo server and returns a client logged in to it.
+func newClient(t *testing.T, cfg untis.HTTPConfig) (*untis.Client, *untistest.Server) {
+	t.Helper()
+	srv := untistest.NewServer(untistest.DemoFixtures(time.Now()))
+	t.Cleanup(srv.Close)
+	c, err := untis.NewClient(srv.URL, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Auth(context.Background(), "demo", "demo"); err != nil {
+		t.Fatal(err)
+	}
+	return c, srv
+}
+
+func TestRetryServerError(t *testing.T) {
+	c, srv := newClient(t, untis.HTTPConfig{MaxRetries: 3})
+	srv.Inject(untistest.Fault{Method: "getRooms", Status: http.StatusServiceUnavailable, Times: 2})
+
+	rooms, err := c.Rooms(context.Background())
+	if err != nil {
+		t.Fatalf("Rooms = %v", err)
+	}
+	if len(rooms) == 0 {
+		t.Error("Rooms returned no rooms")
+	}
+	if n := srv.Calls("getRooms"); n != 3 {
+		t.Errorf("getRooms called %d times, want 3", n)
+	}
+}
+
+func TestRetryTimeout(t *testing.T) {
+	const retries = 2
+	c, srv := newClient(t, untis.HTTPConfig{Timeout: 50 * time.Millisecond, MaxRetries: retries})
+	srv.Inject(untistest.Fault{Method: "getRooms", Delay: time.Second})
+
+	_, err := c.Rooms(context.Background())
+	if !errors.Is(err, untis.ErrUnreachable) {
+		t.Fatalf("Rooms = %v, want ErrUnreachable", err)
+	}
+	if n := srv.Calls("getRooms"); n != retries+1 {
+		t.Errorf("getRooms called %d times, want %d", n, retries+1)
+	}
+}
+
+func TestNoTimeout(t *testing.T) {
+	c, srv := newClient(t, untis.HTTPConfig{})
+	srv.Inject(untistest.Fault{Method: "getRooms", Delay: 100 * time.Millisecond, Times: 1})
+
+	if c.Timeout != 0 {
+		t.Fatalf("Timeout = %v, want 0", c.Timeout)
+	}
+	if _, err := c.Rooms(context.Background()); err != nil {
+		t.Fatalf("Rooms = %v", err)
+	}
+}
+
+func TestNoRetryOnRPCError(t *testing.T) {
+	c, srv := newClient(t, untis.HTTPConfig{MaxRetries: 3})
+	srv.Inject(untistest.Fault{Method: "getTeachers", Code: untistest.CodeNoRight})
+
+	if _, err := c.Teachers(context.Background()); !errors.Is(err, untis.ErrNoRight) {
+		t.Fatalf("Teachers = %v, want ErrNoRight", err)
+	}
+	if n := srv.Calls("getTeachers"); n != 1 {
+		t.Errorf("getTeachers called %d times, want 1", n)
+	}
+}
+
+func TestRequestsPerSecond(t *testing.T) {
+	const rps = 20
+	c, _ := newClient(t, untis.HTTPConfig{RequestsPerSecond: rps})
+	interval := time.Second / rps
+
+	// the limiter already let authenticate through
+	const calls = 4
+	start := time.Now()
+	for range calls {
+		if _, err := c.Rooms(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d, want := time.Since(start), (calls-1)*interval; d < want {
+		t.Errorf("%d calls took %s, want at least %s", calls, d, want)
+	}
+}
@@ -0,0 +1,62 @@
+package untis
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes WebUntis puts into the JSON-RPC error object.
+const (
+	codeBadCredentials   = -8504
+	codeNoRight          = -8509
+	codeNotAuthenticated = -8520
+	codeMethodNotFound   = -32601
+)
+
+var (
+	ErrBadCredentials = errors.New("untis: bad credentials")
+	ErrSessionExpired = errors.New("untis: session expired or not authenticated")
+	ErrNoRight        = errors.New("untis: no right for this method")
+	ErrNoSuchMethod   = errors.New("untis: method not found")
+
+	// ErrOutsideSchoolyear is returned for date ranges that lie outside
+	// every school year.
+	ErrOutsideSchoolyear = errors.New("untis: outside of the school year")
+
+	// ErrUnreachable wraps errors that kept a request from reaching the
+	// server or its answer, such as a missing network, a failed DNS lookup, a
+	// timeout or a dropped connection.
+	ErrUnreachable = errors.New("untis: server unreachable")
+
+	// ErrServer wraps responses with a 5xx or 429 status.
+	ErrServer = errors.New("untis: server error")
+
+	// ErrNotRecorded is returned in replay mode for requests the recording
+	// has no answer to.
+	ErrNotRecorded = errors.New("untis: not in the recording")
+)
+
+// RPCError is the error object of a JSON-RPC response. It matches the
+// sentinel errors above with errors.Is.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("untis: rpc error %d: %s", e.Code, e.Message)
+}
+
+func (e *RPCError) Is(target error) bool {
+	switch e.Code {
+	case codeBadCredentials:
+		return target == ErrBadCredentials
+	case codeNotAuthenticated:
+		return target == ErrSessionExpired
+	case codeNoRight:
+		return target == ErrNoRight
+	case codeMethodNotFound:
+		return target == ErrNoSuchMethod
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package untis
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+type exam struct {
+	ID        int    `json:"id"`
+	Classes   []int  `json:"classes"`
+	Teachers  []int  `json:"teachers"`
+	Rooms     []int  `json:"rooms"`
+	Subject   int    `json:"subject"`
+	Date      int    `json:"date"`
+	StartTime int    `json:"startTime"`
+	EndTime   int    `json:"endTime"`
+	Name      string `json:"name"`
+	Text      string `json:"text"`
+}
+
+// Exam is an exam with its IDs resolved to names and dates formatted like
+// NamedTimetableEntry.
+type Exam struct {
+	ID         int      `json:"id"`
+	ExamTypeID int      `json:"examTypeId"`
+	Name       string   `json:"name,omitempty"`
+	Text       string   `json:"text,omitempty"`
+	Date       string   `json:"date"`
+	StartTime  string   `json:"startTime"`
+	EndTime    string   `json:"endTime"`
+	Subject    string   `json:"subject"`
+	Classes    []string `json:"classes"`
+	Teachers   []string `json:"teachers"`
+	Rooms      []string `json:"rooms"`
+}
+
+// ExamType is a kind of exam the school defines, such as a written test.
+type ExamType struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	LongName        string `json:"longName"`
+	ShowInTimetable bool   `json:"showInTimetable"`
+}
+
+type examParams struct {
+	ExamTypeID int    `json:"examTypeId"`
+	StartDate  string `json:"startDate"`
+	EndDate    string `json:"endDate"`
+}
+
+// ExamTypes returns the exam types of the school.
+func (c *Client) ExamTypes(ctx context.Context) ([]ExamType, error) {
+	var result []ExamType
+	if err := c.Call(ctx, "getExamTypes", nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Exams returns the exams of type examTypeID from from to to (both
+// inclusive), sorted by date and start time. Names are resolved against the
+// client's master data.
+func (c *Client) Exams(ctx context.Context, examTypeID int, from, to time.Time) ([]Exam, error) {
+	p := examParams{examTypeID, from.Format("20060102"), to.Format("20060102")}
+	var result []exam
+	if err := c.Call(ctx, "getExams", p, &result); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date < result[j].Date
+		}
+		return result[i].StartTime < result[j].StartTime
+	})
+
+	names := c.nameMaps()
+	exams := make([]Exam, 0, len(result))
+	for _, e := range result {
+		exams = append(exams, Exam{
+			ID:         e.ID,
+			ExamTypeID: examTypeID,
+			Name:       e.Name,
+			Text:       e.Text,
+			Date:       formatDate(e.Date),
+			StartTime:  formatTime(e.StartTime),
+			EndTime:    formatTime(e.EndTime),
+			Subject:    names.subjects[e.Subject],
+			Classes:    lookupNames(e.Classes, names.classes),
+			Teachers:   lookupNames(e.Teachers, names.teachers),
+			Rooms:      lookupNames(e.Rooms, names.rooms),
+		})
+	}
+	return exams, nil
+}
+
+// lookupNames returns the names of ids, skipping unknown ones.
+func lookupNames(ids []int, names map[int]string) []string {
+	var resolved []string
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,88 @@
+package untis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// DefaultTimeout is a sensible limit for a single request.
+const DefaultTimeout = 15 * time.Second
+
+// HTTPConfig configures the HTTP client NewClient builds.
+type HTTPConfig struct {
+	Timeout time.Duration // per request; zero means no limit
+	Proxy   string        // proxy URL; empty uses HTTPS_PROXY and friends
+	CAFile  string        // PEM file with certificates to trust besides the system ones
+
+	// MaxRetries is how often a call that failed transiently is repeated.
+	MaxRetries int
+	// RequestsPerSecond limits the requests of all calls together; zero
+	// means no limit.
+	RequestsPerSecond float64
+
+	// RecordDir, if set, saves every request and response there with
+	// credentials redacted.
+	RecordDir string
+	// ReplayDir, if set, answers all requests from traffic recorded there
+	// instead of contacting the server.
+	ReplayDir string
+}
+
+// newHTTPClient builds an http.Client from cfg. The per-request timeout is
+// applied by Client.call, not here.
+func newHTTPClient(cfg HTTPConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != "" {
+		proxy, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if cfg.CAFile != "" {
+		pool, err := loadCertPool(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	var rt http.RoundTripper = transport
+	if cfg.ReplayDir != "" {
+		replay, err := newReplayer(cfg.ReplayDir)
+		if err != nil {
+			return nil, err
+		}
+		rt = replay
+	}
+	if cfg.RecordDir != "" {
+		record, err := newRecorder(cfg.RecordDir, rt)
+		if err != nil {
+			return nil, err
+		}
+		rt = record
+	}
+	return &http.Client{Transport: rt}, nil
+}
+
+// loadCertPool returns the system certificates plus the ones in the PEM file
+// path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
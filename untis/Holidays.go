@@ -0,0 +1,84 @@
+package untis
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+)
+
+type holiday struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	LongName  string `json:"longName"`
+	StartDate int    `json:"startDate"`
+	EndDate   int    `json:"endDate"`
+}
+
+// Holiday is a school holiday, with dates formatted like
+// NamedTimetableEntry.Date. StartDate and EndDate are both inclusive.
+type Holiday struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	LongName  string `json:"longName"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+// Start returns the first day of the holiday.
+func (h Holiday) Start() time.Time {
+	t, _ := time.ParseInLocation(DateLayout, h.StartDate, time.Local)
+	return t
+}
+
+// End returns the last day of the holiday.
+func (h Holiday) End() time.Time {
+	t, _ := time.ParseInLocation(DateLayout, h.EndDate, time.Local)
+	return t
+}
+
+// Contains reports whether day falls into the holiday.
+func (h Holiday) Contains(day time.Time) bool {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+	return !date.Before(h.Start()) && !date.After(h.End())
+}
+
+// Holidays returns the school holidays, sorted by start date.
+func (c *Client) Holidays(ctx context.Context) ([]Holiday, error) {
+	var result []holiday
+	if err := c.Call(ctx, "getHolidays", nil, &result); err != nil {
+		return nil, err
+	}
+
+	holidays := make([]Holiday, 0, len(result))
+	for _, h := range result {
+		holidays = append(holidays, Holiday{h.ID, h.Name, h.LongName, formatDate(h.StartDate), formatDate(h.EndDate)})
+	}
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Start().Before(holidays[j].Start())
+	})
+
+	log.Println("Updated Holidays")
+	return holidays, nil
+}
+
+// HolidayOn returns the holiday that day falls into, if any.
+func HolidayOn(holidays []Holiday, day time.Time) (Holiday, bool) {
+	for _, h := range holidays {
+		if h.Contains(day) {
+			return h, true
+		}
+	}
+	return Holiday{}, false
+}
+
+// NextHoliday returns the first holiday that starts after day. holidays must
+// be sorted by start date, as Holidays returns them.
+func NextHoliday(holidays []Holiday, day time.Time) (Holiday, bool) {
+	for _, h := range holidays {
+		if h.Start().After(day) {
+			return h, true
+		}
+	}
+	return Holiday{}, false
+}
@@ -0,0 +1,15 @@
+package untis
+
+import (
+	"context"
+	"time"
+)
+
+// LatestImportTime returns when the school last imported data into WebUntis.
+func (c *Client) LatestImportTime(ctx context.Context) (time.Time, error) {
+	var result int64
+	if err := c.Call(ctx, "getLatestImportTime", nil, &result); err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(result), nil
+}
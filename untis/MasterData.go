@@ -0,0 +1,75 @@
+package untis
+
+// MasterData holds the element lists that timetables, substitutions and
+// exams are resolved against.
+type MasterData struct {
+	Rooms    []Room    `json:"rooms"`
+	Classes  []Class   `json:"classes"`
+	Subjects []Subject `json:"subjects"`
+	Teachers []Teacher `json:"teachers"`
+}
+
+// idMaps maps element IDs to their short names, one map per element kind.
+type idMaps struct {
+	rooms    map[int]string
+	classes  map[int]string
+	subjects map[int]string
+	teachers map[int]string
+}
+
+func (md MasterData) idMaps() idMaps {
+	m := idMaps{
+		rooms:    make(map[int]string),
+		classes:  make(map[int]string),
+		subjects: make(map[int]string),
+		teachers: make(map[int]string),
+	}
+	for _, r := range md.Rooms {
+		m.rooms[r.ID] = r.Name
+	}
+	for _, cl := range md.Classes {
+		m.classes[cl.ID] = cl.Name
+	}
+	for _, s := range md.Subjects {
+		m.subjects[s.ID] = s.Name
+	}
+	for _, t := range md.Teachers {
+		m.teachers[t.ID] = t.Name
+	}
+	return m
+}
+
+// Elements lists every class, teacher, room and subject as an element whose
+// timetable can be requested.
+func (md MasterData) Elements() []NamedElement {
+	var elements []NamedElement
+	for _, cl := range md.Classes {
+		elements = append(elements, NamedElement{Element{ElementClass, cl.ID}, cl.Name, cl.LongName})
+	}
+	for _, t := range md.Teachers {
+		elements = append(elements, NamedElement{Element{ElementTeacher, t.ID}, t.Name, t.LongName})
+	}
+	for _, r := range md.Rooms {
+		elements = append(elements, NamedElement{Element{ElementRoom, r.ID}, r.Name, r.LongName})
+	}
+	for _, s := range md.Subjects {
+		elements = append(elements, NamedElement{Element{ElementSubject, s.ID}, s.Name, s.LongName})
+	}
+	return elements
+}
+
+// SetMasterData makes the client resolve names against md.
+func (c *Client) SetMasterData(md MasterData) {
+	names := md.idMaps()
+	c.mu.Lock()
+	c.names = names
+	c.mu.Unlock()
+}
+
+// nameMaps returns the maps set by SetMasterData. They are never modified,
+// so callers may read them without holding the lock.
+func (c *Client) nameMaps() idMaps {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.names
+}
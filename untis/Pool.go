@@ -0,0 +1,24 @@
+package untis
+
+import "sync"
+
+// maxWorkers bounds the number of requests Main sends at the same time.
+const maxWorkers = 4
+
+// runBounded runs jobs on at most workers goroutines and returns their errors
+// in the order of jobs. Jobs start in order, so a job may wait for the
+// result of an earlier one as long as fewer than workers jobs wait at once.
+func runBounded(workers int, jobs []func() error) []error {
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			errs[i] = job()
+		})
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,277 @@
+package untis
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replaces secrets in recorded traffic.
+const redacted = "REDACTED"
+
+// manifestFile names the manifest of a recording. It does not match the
+// pattern of the exchange files, so replayers do not take it for one.
+const manifestFile = "manifest.json"
+
+// manifest describes a recording.
+type manifest struct {
+	// RecordedAt is when the recording started. Replays compute the current
+	// week from it, so they ask for the dates that were recorded.
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// exchange is one recorded JSON-RPC request and its response.
+type exchange struct {
+	Method   string          `json:"method"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// rpcCall is the part of a JSON-RPC request that identifies an exchange.
+type rpcCall struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// recorder is an http.RoundTripper that saves every exchange as a numbered
+// JSON file in dir, with credentials and session IDs redacted.
+type recorder struct {
+	dir  string
+	next http.RoundTripper
+
+	mu  sync.Mutex
+	seq int
+}
+
+// newRecorder records the traffic of next into dir, creating dir if needed,
+// and writes the manifest of the recording.
+func newRecorder(dir string, next http.RoundTripper) (*recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating record directory: %w", err)
+	}
+	data, err := json.MarshalIndent(manifest{RecordedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, manifestFile), data, 0o644); err != nil {
+		return nil, fmt.Errorf("writing manifest: %w", err)
+	}
+	return &recorder{dir: dir, next: next}, nil
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	var rpc rpcCall
+	if json.Unmarshal(reqBody, &rpc) != nil {
+		return resp, nil
+	}
+	ex := exchange{Method: rpc.Method, Params: redactParams(rpc.Method, rpc.Params), Status: resp.StatusCode}
+	if json.Valid(respBody) {
+		ex.Response = redactResponse(rpc.Method, respBody)
+	}
+	if err := r.save(ex); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", rpc.Method, err)
+	}
+	return resp, nil
+}
+
+func (r *recorder) save(ex exchange) error {
+	r.mu.Lock()
+	r.seq++
+	name := fmt.Sprintf("%04d-%s.json", r.seq, ex.Method)
+	r.mu.Unlock()
+
+	data, err := json.MarshalIndent(ex, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.dir, name), data, 0o644)
+}
+
+// redactParams hides the credentials sent to authenticate.
+func redactParams(method string, params json.RawMessage) json.RawMessage {
+	if method != "authenticate" {
+		return params
+	}
+	return redactFields(params, "user", "password")
+}
+
+// redactResponse hides the session ID authenticate returns.
+func redactResponse(method string, body []byte) json.RawMessage {
+	if method != "authenticate" {
+		return body
+	}
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil || resp["result"] == nil {
+		return body
+	}
+	resp["result"] = redactFields(resp["result"], "sessionId")
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+// redactFields replaces the given fields of the JSON object data.
+func redactFields(data json.RawMessage, fields ...string) json.RawMessage {
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return data
+	}
+	for _, field := range fields {
+		if _, ok := obj[field]; ok {
+			obj[field] = redacted
+		}
+	}
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// replayer is an http.RoundTripper that answers from exchanges saved by a
+// recorder instead of contacting the server.
+type replayer struct {
+	mu        sync.Mutex
+	exchanges map[string][]exchange // by method, in recording order
+	served    map[string]int
+}
+
+// newReplayer loads the exchanges recorded in dir.
+func newReplayer(dir string) (*replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "[0-9]*-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded traffic in %s", dir)
+	}
+	sort.Strings(files)
+
+	r := &replayer{exchanges: make(map[string][]exchange), served: make(map[string]int)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var ex exchange
+		if err := json.Unmarshal(data, &ex); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		r.exchanges[ex.Method] = append(r.exchanges[ex.Method], ex)
+	}
+	return r, nil
+}
+
+// replayOffset returns how far the recording in dir lies in the past, as a
+// negative duration. Recordings without a manifest are replayed in the
+// present.
+func replayOffset(dir string) (time.Duration, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return 0, fmt.Errorf("reading %s: %w", manifestFile, err)
+	}
+	if m.RecordedAt.IsZero() {
+		return 0, nil
+	}
+	return time.Until(m.RecordedAt), nil
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body rpcCall
+	if req.Body != nil {
+		err := json.NewDecoder(req.Body).Decode(&body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("replay: decoding request: %w", err)
+		}
+	}
+
+	ex, ok := r.find(body.Method, redactParams(body.Method, body.Params))
+	if !ok {
+		return nil, fmt.Errorf("replay %s: %w", body.Method, ErrNotRecorded)
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(ex.Response)),
+		ContentLength: int64(len(ex.Response)),
+		Request:       req,
+	}, nil
+}
+
+// find returns the recorded exchange of method with equal params. Without
+// one, as for a timetable recorded on another day, the exchanges of method
+// are served in turn, the last one repeating.
+func (r *replayer) find(method string, params json.RawMessage) (exchange, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	candidates := r.exchanges[method]
+	if len(candidates) == 0 {
+		return exchange{}, false
+	}
+	for _, ex := range candidates {
+		if sameJSON(ex.Params, params) {
+			return ex, true
+		}
+	}
+	i := min(r.served[method], len(candidates)-1)
+	r.served[method]++
+	return candidates[i], true
+}
+
+// sameJSON reports whether a and b encode the same value.
+func sameJSON(a, b json.RawMessage) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return strings.TrimSpace(string(a)) == strings.TrimSpace(string(b))
+	}
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return bytes.Equal(ca, cb)
+}
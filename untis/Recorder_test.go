@@ -0,0 +1,106 @@
+package untis_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	untis "UntisTui/untis"
+	"UntisTui/untis/untistest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	srv := untistest.NewServer(untistest.DemoFixtures(time.Now()))
+	defer srv.Close()
+	recording, err := untis.NewClient(srv.URL, untis.HTTPConfig{RecordDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := untis.Main(ctx, recording, nil, "demo", "demo")
+	if err != nil {
+		t.Fatalf("Main while recording = %v", err)
+	}
+
+	auth, err := os.ReadFile(filepath.Join(dir, "0001-authenticate.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(auth), `"demo"`) || strings.Contains(string(auth), "session-") {
+		t.Errorf("credentials or session ID not redacted:\n%s", auth)
+	}
+
+	replaying, err := untis.NewClient("http://replay.invalid", untis.HTTPConfig{ReplayDir: dir, MaxRetries: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := untis.Main(ctx, replaying, nil, "anyone", "anything")
+	if err != nil {
+		t.Fatalf("Main while replaying = %v", err)
+	}
+	if len(replayed.Week[3]) != len(snap.Week[3]) || len(replayed.Rooms) != len(snap.Rooms) {
+		t.Errorf("replayed snapshot differs from the recorded one")
+	}
+
+	// nothing recorded getExamTypes; that must fail at once, not be retried
+	start := time.Now()
+	if _, err := replaying.ExamTypes(ctx); !errors.Is(err, untis.ErrNotRecorded) || errors.Is(err, untis.ErrUnreachable) {
+		t.Errorf("ExamTypes = %v, want only ErrNotRecorded", err)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("missing recording took %s, was it retried?", d)
+	}
+}
+
+func TestReplayAnotherWeek(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	recordedAt := time.Now().AddDate(0, 0, -14)
+	srv := untistest.NewServer(untistest.DemoFixtures(recordedAt))
+	defer srv.Close()
+	recording, err := untis.NewClient(srv.URL, untis.HTTPConfig{RecordDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fixtures lie two weeks back, so this week is empty or, around the
+	// start of a school year, outside of it
+	if _, err := untis.Main(ctx, recording, nil, "demo", "demo"); err != nil && !errors.Is(err, untis.ErrOutsideSchoolyear) {
+		t.Fatalf("Main while recording = %v", err)
+	}
+	person, err := recording.PersonElement()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recording.Week(ctx, recordedAt, person); err != nil {
+		t.Fatalf("Week while recording = %v", err)
+	}
+	// pretend the recording was made two weeks ago
+	data, err := json.Marshal(map[string]time.Time{"recordedAt": recordedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	replaying, err := untis.NewClient("http://replay.invalid", untis.HTTPConfig{ReplayDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := untis.Monday(replaying.Now()), untis.Monday(recordedAt); got.Format(untis.DateLayout) != want.Format(untis.DateLayout) {
+		t.Errorf("replay runs in the week of %v, want %v", got, want)
+	}
+	snap, err := untis.Main(ctx, replaying, nil, "anyone", "anything")
+	if err != nil {
+		t.Fatalf("Main while replaying = %v", err)
+	}
+	if len(snap.Week[3]) != 3 {
+		t.Errorf("replayed Thursday has %d lessons, want 3", len(snap.Week[3]))
+	}
+}
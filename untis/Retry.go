@@ -0,0 +1,100 @@
+package untis
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	retryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+// retryable reports whether err is worth another attempt: the server could
+// not be reached, timed out, dropped the connection or answered with a 5xx
+// or 429 status.
+func retryable(err error) bool {
+	return errors.Is(err, ErrUnreachable) || errors.Is(err, ErrServer)
+}
+
+// backoff returns the delay before retry number attempt (starting at 0):
+// a random duration up to an exponentially growing cap.
+func backoff(attempt int) time.Duration {
+	limit := retryBaseDelay << attempt
+	if limit <= 0 || limit > retryMaxDelay {
+		limit = retryMaxDelay
+	}
+	return rand.N(limit) + 1
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimiter spaces requests at least interval apart. It is shared by all
+// calls of a Client.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newRateLimiter allows perSecond requests per second. A zero or negative
+// rate returns nil, which never waits.
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next request may be sent or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	if d := time.Until(slot); d > 0 {
+		return sleep(ctx, d)
+	}
+	return nil
+}
+
+// send is call with rate limiting and retries of transient failures.
+func (c *Client) send(ctx context.Context, method string, params any, result any) error {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+		err := c.call(ctx, method, params, result)
+		if err == nil || !retryable(err) || attempt >= c.MaxRetries {
+			return err
+		}
+		delay := backoff(attempt)
+		log.Printf("%v, retrying in %s", err, delay.Round(time.Millisecond))
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package untis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSleepStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("sleep = %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("sleep took %s after cancel", d)
+	}
+}
+
+func TestLimiterWaitStopsOnCancel(t *testing.T) {
+	l := newRateLimiter(1.0 / 3600) // one request per hour
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("first wait = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := l.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("wait = %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("wait took %s after cancel", d)
+	}
+}
+
+func TestLimiterSpacesRequests(t *testing.T) {
+	const rps = 20
+	l := newRateLimiter(rps)
+	interval := time.Second / rps
+
+	var times []time.Time
+	for range 5 {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		times = append(times, time.Now())
+	}
+	// a late wakeup shortens the gap to the next request, so each request is
+	// measured from the first
+	for i := 1; i < len(times); i++ {
+		if d, want := times[i].Sub(times[0]), time.Duration(i)*interval; d < want-time.Millisecond {
+			t.Errorf("request %d came %s after the first, want at least %s", i, d, want)
+		}
+	}
+}
+
+func TestNilLimiterNeverWaits(t *testing.T) {
+	var l *rateLimiter
+	if newRateLimiter(0) != nil {
+		t.Fatal("newRateLimiter(0) is not nil")
+	}
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("wait = %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package untis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+type schoolyear struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	StartDate int    `json:"startDate"`
+	EndDate   int    `json:"endDate"`
+}
+
+// Schoolyear is a school year, with dates formatted like
+// NamedTimetableEntry.Date. StartDate and EndDate are both inclusive.
+type Schoolyear struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+func (y schoolyear) named() Schoolyear {
+	return Schoolyear{y.ID, y.Name, formatDate(y.StartDate), formatDate(y.EndDate)}
+}
+
+// Start returns the first day of the school year.
+func (y Schoolyear) Start() time.Time {
+	t, _ := time.ParseInLocation(DateLayout, y.StartDate, time.Local)
+	return t
+}
+
+// End returns the last day of the school year.
+func (y Schoolyear) End() time.Time {
+	t, _ := time.ParseInLocation(DateLayout, y.EndDate, time.Local)
+	return t
+}
+
+// Schoolyears returns all school years known to the server. The result is
+// cached on the client.
+func (c *Client) Schoolyears(ctx context.Context) ([]Schoolyear, error) {
+	c.mu.Lock()
+	years := c.schoolyears
+	c.mu.Unlock()
+	if years != nil {
+		return years, nil
+	}
+	var result []schoolyear
+	if err := c.Call(ctx, "getSchoolyears", nil, &result); err != nil {
+		return nil, err
+	}
+	years = make([]Schoolyear, 0, len(result))
+	for _, y := range result {
+		years = append(years, y.named())
+	}
+	c.mu.Lock()
+	c.schoolyears = years
+	c.mu.Unlock()
+	return years, nil
+}
+
+// CurrentSchoolyear returns the school year that is active today.
+func (c *Client) CurrentSchoolyear(ctx context.Context) (Schoolyear, error) {
+	var result schoolyear
+	if err := c.Call(ctx, "getCurrentSchoolyear", nil, &result); err != nil {
+		return Schoolyear{}, err
+	}
+	return result.named(), nil
+}
+
+// clampToSchoolyear limits from and to to the school year that from, or
+// failing that to, falls into. A range that touches no school year returns
+// ErrOutsideSchoolyear. If the school years cannot be read, or the server
+// knows none, the range is returned unchanged.
+func (c *Client) clampToSchoolyear(ctx context.Context, from, to time.Time) (time.Time, time.Time, error) {
+	years, err := c.Schoolyears(ctx)
+	if err != nil {
+		log.Printf("Could not read school years, not clamping range: %v", err)
+		return from, to, nil
+	}
+	if len(years) == 0 {
+		log.Println("Server lists no school years, not clamping range")
+		return from, to, nil
+	}
+	for _, y := range years {
+		start, end := y.Start(), y.End().AddDate(0, 0, 1)
+		if !to.Before(start) && from.Before(end) {
+			if from.Before(start) {
+				from = start
+			}
+			if !to.Before(end) {
+				to = y.End()
+			}
+			return from, to, nil
+		}
+	}
+	return from, to, fmt.Errorf("%s to %s: %w", from.Format(DateLayout), to.Format(DateLayout), ErrOutsideSchoolyear)
+}
@@ -0,0 +1,34 @@
+package untis
+
+import "time"
+
+const snapshotFile = "snapshot.json"
+
+// Snapshot is everything the TUI shows, as fetched by Main. It is kept in
+// the Store so the next start can skip fetching when nothing changed.
+type Snapshot struct {
+	FetchedAt  time.Time `json:"fetchedAt"` // last complete sync or check that nothing changed
+	ImportTime int64     `json:"importTime"`
+	WeekStart  string    `json:"weekStart"`
+	Person     Element   `json:"person"`
+	MasterData
+	Timegrid   []Period                 `json:"timegrid"`
+	Holidays   []Holiday                `json:"holidays"`
+	Schoolyear Schoolyear               `json:"schoolyear"`
+	StatusData StatusData               `json:"statusData"`
+	Week       [5][]NamedTimetableEntry `json:"week"`
+}
+
+// LoadSnapshot reads the snapshot written by the last complete sync.
+func (s *Store) LoadSnapshot() (*Snapshot, error) {
+	var snap Snapshot
+	if err := s.Read(snapshotFile, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// SaveSnapshot replaces the cached snapshot with snap.
+func (s *Store) SaveSnapshot(snap *Snapshot) error {
+	return s.Write(snapshotFile, snap)
+}
@@ -0,0 +1,60 @@
+package untis
+
+import (
+	"context"
+	"log"
+)
+
+// StatusColors are the colors the school assigned to a lesson type or code,
+// as hex strings without a leading "#".
+type StatusColors struct {
+	ForeColor string `json:"foreColor"`
+	BackColor string `json:"backColor"`
+}
+
+// StatusData maps lesson types (ls, oh, sb, bs, ex) and codes (cancelled,
+// irregular) to their colors.
+type StatusData struct {
+	LsTypes map[string]StatusColors `json:"lstypes"`
+	Codes   map[string]StatusColors `json:"codes"`
+}
+
+// statusData is the wire format, which wraps every entry in its own object.
+type statusData struct {
+	LsTypes []map[string]StatusColors `json:"lstypes"`
+	Codes   []map[string]StatusColors `json:"codes"`
+}
+
+// Colors returns the colors of entry. A code such as cancelled wins over
+// the lesson type; entries without a lesson type count as regular lessons.
+func (d StatusData) Colors(entry NamedTimetableEntry) (StatusColors, bool) {
+	if colors, ok := d.Codes[entry.Code]; ok && entry.Code != "" {
+		return colors, true
+	}
+	lsType := entry.LsType
+	if lsType == "" {
+		lsType = "ls"
+	}
+	colors, ok := d.LsTypes[lsType]
+	return colors, ok
+}
+
+// StatusData returns the school's status colors.
+func (c *Client) StatusData(ctx context.Context) (StatusData, error) {
+	var result statusData
+	if err := c.Call(ctx, "getStatusData", nil, &result); err != nil {
+		return StatusData{}, err
+	}
+	log.Println("Updated StatusData")
+	return StatusData{flattenStatus(result.LsTypes), flattenStatus(result.Codes)}, nil
+}
+
+func flattenStatus(entries []map[string]StatusColors) map[string]StatusColors {
+	m := make(map[string]StatusColors)
+	for _, entry := range entries {
+		for key, colors := range entry {
+			m[key] = colors
+		}
+	}
+	return m
+}
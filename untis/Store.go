@@ -0,0 +1,54 @@
+package untis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Store keeps the cached WebUntis data as JSON files in one directory.
+type Store struct {
+	Dir string
+}
+
+// DefaultStoreDir returns $XDG_CACHE_HOME/untistui, or the platform's
+// equivalent.
+func DefaultStoreDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "untistui"), nil
+}
+
+// NewStore returns a store rooted in dir, creating dir if needed.
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	return &Store{Dir: dir}, nil
+}
+
+// Path returns the path of the file name in the store.
+func (s *Store) Path(name string) string {
+	return filepath.Join(s.Dir, name)
+}
+
+// Read decodes the file name into v.
+func (s *Store) Read(name string, v any) error {
+	data, err := os.ReadFile(s.Path(name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Write stores v as indented JSON in the file name.
+func (s *Store) Write(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.Path(name), data, 0o644)
+}
@@ -0,0 +1,119 @@
+package untis
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+type substitution struct {
+	Type      string         `json:"type"`
+	LsID      int            `json:"lsid"`
+	Date      int            `json:"date"`
+	StartTime int            `json:"startTime"`
+	EndTime   int            `json:"endTime"`
+	Kl        []substElement `json:"kl"`
+	Su        []substElement `json:"su"`
+	Ro        []substElement `json:"ro"`
+	Te        []substElement `json:"te"`
+	Txt       string         `json:"txt"`
+}
+
+type substElement struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	OrgID   int    `json:"orgid,omitempty"`
+	OrgName string `json:"orgname,omitempty"`
+}
+
+// Substitution is one entry of the substitution plan. Type is one of cancel,
+// subst, add, shift, rmchg and others the server may send. The *Org fields
+// follow the same rules as in NamedTimetableEntry.
+type Substitution struct {
+	Type      string   `json:"type"`
+	LessonID  int      `json:"lsid"`
+	Date      string   `json:"date"`
+	StartTime string   `json:"startTime"`
+	EndTime   string   `json:"endTime"`
+	Kl        []string `json:"kl"`
+	Su        []string `json:"su"`
+	Ro        []string `json:"ro"`
+	Te        []string `json:"te"`
+	RoOrg     []string `json:"roOrg,omitempty"`
+	TeOrg     []string `json:"teOrg,omitempty"`
+	Txt       string   `json:"txt,omitempty"`
+}
+
+type substitutionParams struct {
+	StartDate    string `json:"startDate"`
+	EndDate      string `json:"endDate"`
+	DepartmentID int    `json:"departmentId"`
+}
+
+// Substitutions fetches the substitution plan from from to to (both
+// inclusive). departmentID 0 returns all departments. Names are resolved
+// against the client's master data, falling back to the names the server
+// sends.
+func (c *Client) Substitutions(ctx context.Context, from, to time.Time, departmentID int) ([]Substitution, error) {
+	p := substitutionParams{from.Format("20060102"), to.Format("20060102"), departmentID}
+	var result []substitution
+	if err := c.Call(ctx, "getSubstitutions", p, &result); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date < result[j].Date
+		}
+		return result[i].StartTime < result[j].StartTime
+	})
+
+	names := c.nameMaps()
+	substitutions := make([]Substitution, 0, len(result))
+	for _, s := range result {
+		kl, _ := resolveSubstNames(s.Kl, names.classes)
+		su, _ := resolveSubstNames(s.Su, names.subjects)
+		ro, roOrg := resolveSubstNames(s.Ro, names.rooms)
+		te, teOrg := resolveSubstNames(s.Te, names.teachers)
+		substitutions = append(substitutions, Substitution{
+			Type:      s.Type,
+			LessonID:  s.LsID,
+			Date:      formatDate(s.Date),
+			StartTime: formatTime(s.StartTime),
+			EndTime:   formatTime(s.EndTime),
+			Kl:        kl,
+			Su:        su,
+			Ro:        ro,
+			Te:        te,
+			RoOrg:     roOrg,
+			TeOrg:     teOrg,
+			Txt:       s.Txt,
+		})
+	}
+	return substitutions, nil
+}
+
+// resolveSubstNames is resolveNames for substitution elements, which carry
+// their own names as a fallback.
+func resolveSubstNames(elements []substElement, names map[int]string) (resolved []string, orgs []string) {
+	replaced := false
+	for _, e := range elements {
+		name, ok := names[e.ID]
+		if !ok {
+			name = e.Name
+		}
+		org, ok := names[e.OrgID]
+		if !ok {
+			org = e.OrgName
+		}
+		resolved = append(resolved, name)
+		orgs = append(orgs, org)
+		if e.OrgID != 0 {
+			replaced = true
+		}
+	}
+	if !replaced {
+		return resolved, nil
+	}
+	return resolved, orgs
+}
@@ -0,0 +1,62 @@
+package untis
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+)
+
+type timegridDay struct {
+	Day       int        `json:"day"`
+	TimeUnits []timeUnit `json:"timeUnits"`
+}
+
+type timeUnit struct {
+	Name      string `json:"name"`
+	StartTime int    `json:"startTime"`
+	EndTime   int    `json:"endTime"`
+}
+
+// Period is one row of the school's time grid, with times formatted like
+// NamedTimetableEntry.StartTime.
+type Period struct {
+	Name      string `json:"name"`
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+}
+
+// Timegrid returns the school's periods. The grid may differ between
+// weekdays; the periods of all days are merged by start time.
+func (c *Client) Timegrid(ctx context.Context) ([]Period, error) {
+	var result []timegridDay
+	if err := c.Call(ctx, "getTimegridUnits", nil, &result); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	var units []timeUnit
+	for _, day := range result {
+		for _, unit := range day.TimeUnits {
+			if !seen[unit.StartTime] {
+				seen[unit.StartTime] = true
+				units = append(units, unit)
+			}
+		}
+	}
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].StartTime < units[j].StartTime
+	})
+
+	periods := make([]Period, 0, len(units))
+	for i, unit := range units {
+		name := unit.Name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		periods = append(periods, Period{name, formatTime(unit.StartTime), formatTime(unit.EndTime)})
+	}
+
+	log.Println("Updated Timegrid")
+	return periods, nil
+}
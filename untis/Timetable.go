@@ -1,21 +1,15 @@
 package untis
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"os"
+	"sort"
 	"time"
 )
 
-type TimetableResponse struct {
-	Jsonrpc string      `json:"jsonrpc"`
-	ID      string      `json:"id"`
-	Result  []timetable `json:"result"`
-}
+// NamedTimetableEntry is a lesson with its IDs resolved to names. The *Org
+// fields hold the original element of a substitution at the same index as
+// its replacement, or "" where nothing was replaced.
 type NamedTimetableEntry struct {
 	ID           int      `json:"id"`
 	Date         string   `json:"date"`
@@ -26,6 +20,12 @@ type NamedTimetableEntry struct {
 	Kl           []string `json:"kl"`
 	Su           []string `json:"su"`
 	Ro           []string `json:"ro"`
+	Te           []string `json:"te"`
+	KlOrg        []string `json:"klOrg,omitempty"`
+	SuOrg        []string `json:"suOrg,omitempty"`
+	RoOrg        []string `json:"roOrg,omitempty"`
+	TeOrg        []string `json:"teOrg,omitempty"`
+	LsType       string   `json:"lstype,omitempty"`
 	ActivityType string   `json:"activityType"`
 }
 type timetable struct {
@@ -38,22 +38,15 @@ type timetable struct {
 	Kl           []IDObj `json:"kl"`
 	Su           []IDObj `json:"su"`
 	Ro           []IDObj `json:"ro"`
+	Te           []IDObj `json:"te"`
+	LsType       string  `json:"lstype,omitempty"`
 	ActivityType string  `json:"activityType"`
 }
 type IDObj struct {
-	ID int `json:"id"`
+	ID    int `json:"id"`
+	OrgID int `json:"orgid,omitempty"`
 }
 
-type NamedObj struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-}
-type getTimetable struct {
-	ID      string `json:"id"`
-	Method  string `json:"method"`
-	Params  params `json:"params"`
-	Jsonrpc string `json:"jsonrpc"`
-}
 type params struct {
 	StartDate string `json:"startDate"`
 	EndDate   string `json:"endDate"`
@@ -67,106 +60,107 @@ type Loginresult struct {
 	KlasseID   int    `json:"klasseId"`
 }
 
-func ReadLoginResultFromFile(path string) (Loginresult, error) {
-	var result Loginresult
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return result, err
-	}
-	err = json.Unmarshal(data, &result)
-	return result, err
-}
+// DateLayout is the time layout of NamedTimetableEntry.Date.
+const DateLayout = "02-01-2006"
 
-func Timetable(cookies []*http.Cookie, date time.Time, weekday string, url string) {
-	loginFile := "login.json"
+// ElementType is the WebUntis element type of a timetable owner.
+type ElementType int
 
-	loginResult, err := ReadLoginResultFromFile(loginFile)
-	if err != nil {
-		log.Printf("Could not read login result for user %s: ", err)
-		return
-	}
-
-	dateStr := date.Format("20060102")
-	g := getTimetable{"If you read this, Hello", "getTimetable", params{dateStr, dateStr, loginResult.PersonID, loginResult.PersonType}, "2.0"}
-	timetablesJSON, err := json.Marshal(g)
-	if err != nil {
-		log.Printf("Error marshaling timetable request: %v", err)
-		return
-	}
-	timetable := bytes.NewReader(timetablesJSON)
+const (
+	ElementClass   ElementType = 1
+	ElementTeacher ElementType = 2
+	ElementSubject ElementType = 3
+	ElementRoom    ElementType = 4
+	ElementStudent ElementType = 5
+)
 
-	req, err := http.NewRequest("GET", url, timetable)
-	if err != nil {
-		log.Printf("Error creating timetable request: %v", err)
-		return
-	}
-	req.Header.Set("Content-Type", "application/json")
-	for _, cookie := range cookies {
-		req.AddCookie(cookie)
-	}
+func (t ElementType) String() string {
+	switch t {
+	case ElementClass:
+		return "Class"
+	case ElementTeacher:
+		return "Teacher"
+	case ElementSubject:
+		return "Subject"
+	case ElementRoom:
+		return "Room"
+	case ElementStudent:
+		return "Student"
+	}
+	return fmt.Sprintf("ElementType(%d)", int(t))
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Printf("Error fetching timetable: %v", err)
-		return
-	}
-	defer resp.Body.Close()
+// Element identifies whose timetable is requested, by WebUntis element
+// type and ID.
+type Element struct {
+	Type ElementType
+	ID   int
+}
 
-	response, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Error reading timetable response: %v", err)
-		return
-	}
+// NamedElement is an Element together with its master data names.
+type NamedElement struct {
+	Element
+	Name     string `json:"name"`
+	LongName string `json:"longName"`
+}
 
-	var Response TimetableResponse
-	if err := json.Unmarshal(response, &Response); err != nil {
-		log.Printf("Error unmarshaling timetable response: %v", err)
-		return
+// PersonElement returns the element of the logged-in user.
+func (c *Client) PersonElement() (Element, error) {
+	c.mu.Lock()
+	session := c.session
+	c.mu.Unlock()
+	if session.SessionID == "" {
+		return Element{}, ErrSessionExpired
 	}
+	return Element{ElementType(session.PersonType), session.PersonID}, nil
+}
 
-	data, err := json.MarshalIndent(Response.Result, "", "  ")
+// TimetableRange fetches the timetable of element from from to to (both
+// inclusive) in one request. The range is clamped to the school year it
+// falls into. The entries are grouped by their Date and sorted by start time.
+func (c *Client) TimetableRange(ctx context.Context, from, to time.Time, element Element) (map[string][]NamedTimetableEntry, error) {
+	lessons, err := c.fetchTimetable(ctx, from, to, element)
 	if err != nil {
-		log.Printf("Error marshaling timetable result: %v", err)
-		return
-	}
-
-	timetableFileTmp := "timetableTmp.json"
-
-	if err := os.WriteFile(timetableFileTmp, data, 0o644); err != nil {
-		log.Printf("Error writing timetable file: %v", err)
-		return
+		return nil, err
 	}
-
-	log.Printf("Updated timetable for user ")
-	setTimetable(weekday)
+	return c.groupByDay(lessons), nil
 }
 
-func LoadIDMap(path string) (map[int]string, error) {
-	data, err := os.ReadFile(path)
+// fetchTimetable returns the unresolved lessons of element from from to to,
+// clamped to the school year.
+func (c *Client) fetchTimetable(ctx context.Context, from, to time.Time, element Element) ([]timetable, error) {
+	from, to, err := c.clampToSchoolyear(ctx, from, to)
 	if err != nil {
 		return nil, err
 	}
-	var objs []NamedObj
-	if err := json.Unmarshal(data, &objs); err != nil {
+	p := params{from.Format("20060102"), to.Format("20060102"), element.ID, int(element.Type)}
+	var result []timetable
+	if err := c.Call(ctx, "getTimetable", p, &result); err != nil {
 		return nil, err
 	}
-	m := make(map[int]string)
-	for _, obj := range objs {
-		m[obj.ID] = obj.Name
-	}
-	return m, nil
+	return result, nil
 }
 
-func LoadTimetable(path string) ([]timetable, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	var entries []timetable
-	if err := json.Unmarshal(data, &entries); err != nil {
-		return nil, err
+// groupByDay resolves lessons and groups them by Date, each day sorted by
+// start time, cancelled lessons after the others at the same time.
+func (c *Client) groupByDay(lessons []timetable) map[string][]NamedTimetableEntry {
+	days := make(map[string][]NamedTimetableEntry)
+	for _, entry := range c.resolveTimetable(lessons) {
+		days[entry.Date] = append(days[entry.Date], entry)
+	}
+	for _, entries := range days {
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].StartTime != entries[j].StartTime {
+				return entries[i].StartTime < entries[j].StartTime
+			}
+			// a cancelled lesson goes after the replacement in its period
+			if cancelled := entries[i].Code == "cancelled"; cancelled != (entries[j].Code == "cancelled") {
+				return !cancelled
+			}
+			return entries[i].ID < entries[j].ID
+		})
 	}
-	return entries, nil
+	return days
 }
 
 func formatTime(t int) string {
@@ -183,27 +177,14 @@ func formatDate(date int) string {
 	return fmt.Sprintf("%s-%s-%s", day, month, year)
 }
 
-func setTimetable(weekday string) {
-	subjects, _ := LoadIDMap("subjects.json")
-	rooms, _ := LoadIDMap("rooms.json")
-	classes, _ := LoadIDMap("classes.json")
-
-	timetableFileTmp := "timetableTmp.json"
-
-	timetableTmp, _ := LoadTimetable(timetableFileTmp)
-
+func (c *Client) resolveTimetable(lessons []timetable) []NamedTimetableEntry {
+	names := c.nameMaps()
 	var namedTimetable []NamedTimetableEntry
-	for _, lesson := range timetableTmp {
-		var klNames, suNames, roNames []string
-		for _, kl := range lesson.Kl {
-			klNames = append(klNames, classes[kl.ID])
-		}
-		for _, su := range lesson.Su {
-			suNames = append(suNames, subjects[su.ID])
-		}
-		for _, ro := range lesson.Ro {
-			roNames = append(roNames, rooms[ro.ID])
-		}
+	for _, lesson := range lessons {
+		klNames, klOrgs := resolveNames(lesson.Kl, names.classes)
+		suNames, suOrgs := resolveNames(lesson.Su, names.subjects)
+		roNames, roOrgs := resolveNames(lesson.Ro, names.rooms)
+		teNames, teOrgs := resolveNames(lesson.Te, names.teachers)
 		namedTimetable = append(namedTimetable, NamedTimetableEntry{
 			ID:           lesson.ID,
 			Date:         formatDate(lesson.Date),
@@ -214,25 +195,36 @@ func setTimetable(weekday string) {
 			Kl:           klNames,
 			Su:           suNames,
 			Ro:           roNames,
+			Te:           teNames,
+			KlOrg:        klOrgs,
+			SuOrg:        suOrgs,
+			RoOrg:        roOrgs,
+			TeOrg:        teOrgs,
+			LsType:       lesson.LsType,
 			ActivityType: lesson.ActivityType,
 		})
 	}
+	return namedTimetable
+}
 
-	data, err := json.MarshalIndent(namedTimetable, "", "  ")
-	if err != nil {
-		log.Printf("Error marshaling named timetable: %v", err)
-		return
+// resolveNames looks up the names of objs. IDs missing from names are
+// skipped, students for example usually may not read the teachers. orgs is
+// nil unless at least one element replaces an original one.
+func resolveNames(objs []IDObj, names map[int]string) (resolved []string, orgs []string) {
+	replaced := false
+	for _, obj := range objs {
+		name, ok := names[obj.ID]
+		if !ok {
+			continue
+		}
+		resolved = append(resolved, name)
+		orgs = append(orgs, names[obj.OrgID])
+		if obj.OrgID != 0 {
+			replaced = true
+		}
 	}
-
-	timetableFilledFileWeekday := "timetableFilled_" + weekday + ".json"
-
-	if err := os.WriteFile(timetableFilledFileWeekday, data, 0o644); err != nil {
-		log.Printf("Error writing timetableFilled file: %v", err)
-		return
+	if !replaced {
+		return resolved, nil
 	}
-	log.Printf("Filled timetable for user")
-
-	// remove temporary timetable file
-	os.Remove("timetableTmp.json")
-	log.Printf("Temporary timetable file removed")
+	return resolved, orgs
 }
@@ -1,11 +1,12 @@
 package untis
 
 import (
-	"net/http"
+	"context"
 	"time"
 )
 
-func getMonday(t time.Time) time.Time {
+// Monday returns the Monday of the week that contains t.
+func Monday(t time.Time) time.Time {
 	offset := int(t.Weekday() - time.Monday)
 	if offset < 0 {
 		offset += 7
@@ -13,25 +14,34 @@ func getMonday(t time.Time) time.Time {
 	return t.AddDate(0, 0, -offset)
 }
 
-func getWeekTable(cookies []*http.Cookie, url string) {
-	now := time.Now()
-	monday := getMonday(now)
-	tuesday := monday.AddDate(0, 0, 1)
-	wednesday := monday.AddDate(0, 0, 2)
-	thursday := monday.AddDate(0, 0, 3)
-	friday := monday.AddDate(0, 0, 4)
-	Weekday := "Monday"
-	Timetable(cookies, monday, Weekday, url)
-	Weekday = "Tuesday"
-	Timetable(cookies, tuesday, Weekday, url)
-	Weekday = "Wednesday"
-	Timetable(cookies, wednesday, Weekday, url)
-	Weekday = "Thursday"
-	Timetable(cookies, thursday, Weekday, url)
-	Weekday = "Friday"
-	Timetable(cookies, friday, Weekday, url)
+// Week fetches the Monday to Friday timetable of element for the week that
+// contains day. For the current week, pass c.Now(), which also works when
+// replaying a recording from another week.
+func (c *Client) Week(ctx context.Context, day time.Time, element Element) ([5][]NamedTimetableEntry, error) {
+	return c.week(ctx, day, element, nil)
 }
 
-func TimetableWeek(cookies []*http.Cookie, url string) {
-	getWeekTable(cookies, url)
+// week is Week, but waits for ready to be closed before resolving names, so
+// the lessons can be fetched while the master data is still loading. A nil
+// ready resolves right away.
+func (c *Client) week(ctx context.Context, day time.Time, element Element, ready <-chan struct{}) ([5][]NamedTimetableEntry, error) {
+	var week [5][]NamedTimetableEntry
+	monday := Monday(day)
+	friday := monday.AddDate(0, 0, 4)
+	lessons, err := c.fetchTimetable(ctx, monday, friday, element)
+	if err != nil {
+		return week, err
+	}
+	if ready != nil {
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return week, ctx.Err()
+		}
+	}
+	days := c.groupByDay(lessons)
+	for i := range week {
+		week[i] = days[monday.AddDate(0, 0, i).Format(DateLayout)]
+	}
+	return week, nil
 }
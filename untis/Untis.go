@@ -2,14 +2,11 @@
 package untis
 
 import (
-	"bytes"
-	"encoding/json"
-	"io"
+	"context"
+	"errors"
+	"fmt"
 	"log"
-	"net/http"
-	"os"
-
-	"github.com/joho/godotenv"
+	"sync"
 )
 
 type Params struct {
@@ -18,74 +15,169 @@ type Params struct {
 	Client   string `json:"client"`
 }
 
-type Login struct {
-	ID     string `json:"id"`
-	Method string `json:"method"`
-	Params Params `json:"params"`
-
-	Jsonrpc string `json:"jsonrpc"`
-}
-type LoginResponse struct {
-	Jsonrpc string      `json:"jsonrpc"`
-	ID      string      `json:"id"`
-	Result  Loginresult `json:"result"`
-}
-
-func init() {
-	godotenv.Overload("../.env")
-}
-
-func Main(user string, password string, url string) {
-	godotenv.Load("../.env")
-	cookies, err := Auth(user, password, url)
-	if err != nil {
-		log.Printf("Authentication failed for user %s: %v", user, err)
-		return
+// Main logs in with c and returns the master data and the current week.
+// If store holds a snapshot for this week and the server reports no import
+// since, the snapshot is returned without fetching. Data that cannot be
+// fetched is taken from the cached snapshot, if any, and the errors are
+// returned alongside. A nil store disables caching. Authentication errors are
+// returned as is with a nil snapshot, so callers can check them with
+// errors.Is against ErrBadCredentials and friends. The session stays open
+// for further calls; callers end it with c.Logout.
+func Main(ctx context.Context, c *Client, store *Store, user string, password string) (*Snapshot, error) {
+	if err := c.Auth(ctx, user, password); err != nil {
+		return nil, fmt.Errorf("authentication failed for user %s: %w", user, err)
 	}
-	Rooms(cookies, url)
-	Classes(cookies, url)
-	Subjects(cookies, url)
-	TimetableWeek(cookies, url)
-	Teachers(cookies, url)
-}
 
-func Auth(user string, password string, url string) ([]*http.Cookie, error) {
-	l := Login{"2023-05-06 15:44:22.215292", "authenticate", Params{user, password, "WebUntis Test"}, "2.0"}
-	loginJSON, err := json.Marshal(l)
-	if err != nil {
-		return nil, err
+	snap := &Snapshot{}
+	if store != nil {
+		if cached, err := store.LoadSnapshot(); err == nil {
+			snap = cached
+		}
 	}
-	login := bytes.NewReader(loginJSON)
 
-	LoginOut, err := http.Post(url, "application/json", login)
+	week := Monday(c.Now()).Format(DateLayout)
+	person, err := c.PersonElement()
 	if err != nil {
 		return nil, err
 	}
-	defer LoginOut.Body.Close()
-
-	cookies := LoginOut.Cookies()
-	log.Printf("Login successful for user: %s", user)
-
-	response, err := io.ReadAll(LoginOut.Body)
-	if err != nil {
-		return nil, err
+	importTime, importErr := c.LatestImportTime(ctx)
+	if importErr != nil {
+		log.Printf("Could not read latest import time, refetching everything: %v", importErr)
+	} else if snap.WeekStart == week && snap.Person == person && snap.ImportTime >= importTime.UnixMilli() {
+		log.Println("No new import since last sync, using cached data")
+		c.SetMasterData(snap.MasterData)
+		snap.FetchedAt = c.Now()
+		if store != nil {
+			if err := store.SaveSnapshot(snap); err != nil {
+				log.Printf("Error writing snapshot: %v", err)
+			}
+		}
+		return snap, nil
 	}
 
-	var Response LoginResponse
-	if err := json.Unmarshal(response, &Response); err != nil {
-		return nil, err
+	// the week is fetched alongside the master data, but only resolved once
+	// all four lists are in
+	var master sync.WaitGroup
+	master.Add(4)
+	masterReady := make(chan struct{})
+	go func() {
+		master.Wait()
+		c.SetMasterData(snap.MasterData)
+		close(masterReady)
+	}()
+
+	errs := runBounded(maxWorkers, []func() error{
+		func() error {
+			defer master.Done()
+			rooms, err := c.Rooms(ctx)
+			if err == nil {
+				snap.Rooms = rooms
+			}
+			return err
+		},
+		func() error {
+			defer master.Done()
+			classes, err := c.Classes(ctx)
+			if err == nil {
+				snap.Classes = classes
+			}
+			return err
+		},
+		func() error {
+			defer master.Done()
+			subjects, err := c.Subjects(ctx)
+			if err == nil {
+				snap.Subjects = subjects
+			}
+			return err
+		},
+		func() error {
+			defer master.Done()
+			teachers, err := c.Teachers(ctx)
+			if err == nil {
+				snap.Teachers = teachers
+			}
+			return err
+		},
+		func() error {
+			days, err := c.week(ctx, c.Now(), person, masterReady)
+			if err == nil {
+				snap.Week, snap.WeekStart, snap.Person = days, week, person
+				log.Println("Updated TimetableWeek")
+			}
+			return err
+		},
+		func() error {
+			periods, err := c.Timegrid(ctx)
+			if err == nil {
+				snap.Timegrid = periods
+			}
+			return err
+		},
+		func() error {
+			holidays, err := c.Holidays(ctx)
+			if err == nil {
+				snap.Holidays = holidays
+			}
+			return err
+		},
+		func() error {
+			year, err := c.CurrentSchoolyear(ctx)
+			if err == nil {
+				snap.Schoolyear = year
+			}
+			return err
+		},
+		func() error {
+			status, err := c.StatusData(ctx)
+			if err == nil {
+				snap.StatusData = status
+			}
+			return err
+		},
+	})
+	<-masterReady
+
+	// data the account may not read stays missing, so it does not force a
+	// refetch on the next run. Without the import time the snapshot is still
+	// saved, but with it unset, so the next run fetches again.
+	if importErr != nil && !errors.Is(importErr, ErrNoRight) && !errors.Is(importErr, ErrNoSuchMethod) {
+		errs = append(errs, fmt.Errorf("latest import time: %w", importErr))
 	}
-
-	data, err := json.MarshalIndent(Response.Result, "", "  ")
-	if err != nil {
-		return nil, err
+	complete := true
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrNoRight) {
+			complete = false
+		}
 	}
-
-	loginFile := "login.json"
-
-	if err := os.WriteFile(loginFile, data, 0o644); err != nil {
-		return nil, err
+	if complete {
+		snap.FetchedAt = c.Now()
+		snap.ImportTime = 0
+		if importErr == nil {
+			snap.ImportTime = importTime.UnixMilli()
+		}
+		if store != nil {
+			if err := store.SaveSnapshot(snap); err != nil {
+				log.Printf("Error writing snapshot: %v", err)
+			}
+		}
 	}
+	return snap, errors.Join(errs...)
+}
 
-	return cookies, nil
+func (c *Client) Auth(ctx context.Context, user string, password string) error {
+	var result Loginresult
+	if err := c.Call(ctx, "authenticate", Params{user, password, "WebUntis Test"}, &result); err != nil {
+		return err
+	}
+	if result.SessionID == "" {
+		return ErrBadCredentials
+	}
+	c.mu.Lock()
+	c.user, c.password = user, password
+	c.session = result
+	c.generation++
+	c.mu.Unlock()
+	log.Printf("Login successful for user: %s", user)
+	return nil
 }
@@ -0,0 +1,127 @@
+package untistest
+
+import (
+	"strconv"
+	"time"
+
+	untis "UntisTui/untis"
+)
+
+// DemoFixtures is a small school: one class with a student, three subjects
+// and a Monday to Friday timetable for the week that contains day, plus the
+// school year, time grid, holidays and status colors untis.Main asks for.
+// The student logs in as "demo" with password "demo". Wednesday's second
+// lesson is cancelled and Thursday's first is held in another room.
+func DemoFixtures(day time.Time) Fixtures {
+	f := Fixtures{
+		User:       "demo",
+		Password:   "demo",
+		PersonType: untis.ElementStudent,
+		PersonID:   1,
+		KlasseID:   1,
+		Rooms: []untis.Room{
+			{ID: 1, Name: "R101", LongName: "Room 101", Active: true},
+			{ID: 2, Name: "LAB", LongName: "Science lab", Active: true},
+		},
+		Classes: []untis.Class{
+			{ID: 1, Name: "10a", LongName: "Class 10a", Active: true, Teacher1: 1},
+		},
+		Subjects: []untis.Subject{
+			{ID: 1, Name: "M", LongName: "Mathematics", Active: true},
+			{ID: 2, Name: "E", LongName: "English", Active: true},
+			{ID: 3, Name: "PH", LongName: "Physics", Active: true},
+		},
+		Teachers: []untis.Teacher{
+			{ID: 1, Name: "SMI", LongName: "Smith", Active: true},
+			{ID: 2, Name: "JON", LongName: "Jones", Active: true},
+		},
+	}
+
+	periods := [][2]int{{800, 845}, {850, 935}, {955, 1040}}
+	monday := untis.Monday(day)
+	f.Results = demoResults(monday, periods)
+	id := 0
+	for d := range 5 {
+		date := dateInt(monday.AddDate(0, 0, d))
+		for p, period := range periods {
+			id++
+			subject := (d+p)%3 + 1
+			lesson := Lesson{
+				ID:        id,
+				Date:      date,
+				StartTime: period[0],
+				EndTime:   period[1],
+				Kl:        []untis.IDObj{{ID: 1}},
+				Su:        []untis.IDObj{{ID: subject}},
+				Ro:        []untis.IDObj{{ID: 1}},
+				Te:        []untis.IDObj{{ID: subject%2 + 1}},
+			}
+			switch {
+			case d == 2 && p == 1:
+				lesson.Code = "cancelled"
+			case d == 3 && p == 0:
+				lesson.Code = "irregular"
+				lesson.Ro = []untis.IDObj{{ID: 2, OrgID: 1}}
+			}
+			f.Timetable = append(f.Timetable, lesson)
+		}
+	}
+	return f
+}
+
+// demoResults answers the methods besides master data and timetable for a
+// school year around monday, with a holiday two weeks later.
+func demoResults(monday time.Time, periods [][2]int) map[string]any {
+	year := monday.Year()
+	if monday.Month() < time.August {
+		year--
+	}
+	schoolyear := map[string]any{
+		"id":        1,
+		"name":      strconv.Itoa(year) + "/" + strconv.Itoa(year+1),
+		"startDate": year*10000 + 801,
+		"endDate":   (year+1)*10000 + 731,
+	}
+
+	var units []map[string]any
+	for i, period := range periods {
+		units = append(units, map[string]any{"name": strconv.Itoa(i + 1), "startTime": period[0], "endTime": period[1]})
+	}
+	var timegrid []map[string]any
+	for weekday := 2; weekday <= 6; weekday++ { // WebUntis counts Sunday as 1
+		timegrid = append(timegrid, map[string]any{"day": weekday, "timeUnits": units})
+	}
+
+	holidayStart := monday.AddDate(0, 0, 14)
+	holiday := map[string]any{
+		"id":        1,
+		"name":      "Demo",
+		"longName":  "Demo holidays",
+		"startDate": dateInt(holidayStart),
+		"endDate":   dateInt(holidayStart.AddDate(0, 0, 4)),
+	}
+
+	colors := func(fore, back string) map[string]any {
+		return map[string]any{"foreColor": fore, "backColor": back}
+	}
+	return map[string]any{
+		"getLatestImportTime":  monday.UnixMilli(),
+		"getSchoolyears":       []any{schoolyear},
+		"getCurrentSchoolyear": schoolyear,
+		"getTimegridUnits":     timegrid,
+		"getHolidays":          []any{holiday},
+		"getStatusData": map[string]any{
+			"lstypes": []any{map[string]any{"ls": colors("000000", "f49f25")}},
+			"codes": []any{
+				map[string]any{"cancelled": colors("000000", "b1b3b4")},
+				map[string]any{"irregular": colors("000000", "a781b5")},
+			},
+		},
+	}
+}
+
+// dateInt returns t as yyyymmdd, the date format of the wire.
+func dateInt(t time.Time) int {
+	date, _ := strconv.Atoi(t.Format("20060102"))
+	return date
+}
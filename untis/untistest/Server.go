@@ -0,0 +1,313 @@
+// Package untistest provides a fake WebUntis JSON-RPC server for tests and
+// demos, in the spirit of net/http/httptest.
+package untistest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	untis "UntisTui/untis"
+)
+
+// Error codes the fake server answers with, as WebUntis does.
+const (
+	CodeBadCredentials   = -8504
+	CodeNoRight          = -8509
+	CodeNotAuthenticated = -8520
+	CodeMethodNotFound   = -32601
+)
+
+const sessionCookie = "JSESSIONID"
+
+// Lesson is a timetable entry in the wire format of getTimetable. Date is
+// yyyymmdd, StartTime and EndTime are hhmm.
+type Lesson struct {
+	ID           int           `json:"id"`
+	Date         int           `json:"date"`
+	StartTime    int           `json:"startTime"`
+	EndTime      int           `json:"endTime"`
+	Code         string        `json:"code,omitempty"`
+	Statflags    string        `json:"statflags,omitempty"`
+	Kl           []untis.IDObj `json:"kl"`
+	Su           []untis.IDObj `json:"su"`
+	Ro           []untis.IDObj `json:"ro"`
+	Te           []untis.IDObj `json:"te"`
+	LsType       string        `json:"lstype,omitempty"`
+	ActivityType string        `json:"activityType"`
+}
+
+// Fixtures is the data the server hands out. Only User with Password may log
+// in; the session then belongs to the person PersonType/PersonID.
+type Fixtures struct {
+	User       string
+	Password   string
+	PersonType untis.ElementType
+	PersonID   int
+	KlasseID   int
+
+	Rooms     []untis.Room
+	Classes   []untis.Class
+	Subjects  []untis.Subject
+	Teachers  []untis.Teacher
+	Timetable []Lesson
+
+	// Results answers the methods the server does not implement itself,
+	// such as getSchoolyears or getHolidays, in their wire format.
+	Results map[string]any
+}
+
+// Fault makes calls of Method fail. Status answers with that HTTP status,
+// otherwise Code is sent as a JSON-RPC error. Delay holds the answer back,
+// long enough delays simulate a hung server. Times limits how many calls fail,
+// zero means all of them. An empty Method matches every method.
+type Fault struct {
+	Method string
+	Status int
+	Code   int
+	Delay  time.Duration
+	Times  int
+}
+
+// Server is a running fake WebUntis server. Point an untis.Client at URL.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	fixtures Fixtures
+	results  map[string]any
+	faults   []*Fault
+	sessions map[string]bool
+	calls    map[string]int
+	nextID   int
+}
+
+// NewServer starts a server that serves fixtures. Callers must Close it.
+func NewServer(fixtures Fixtures) *Server {
+	s := &Server{
+		fixtures: fixtures,
+		results:  make(map[string]any),
+		sessions: make(map[string]bool),
+		calls:    make(map[string]int),
+	}
+	for method, result := range fixtures.Results {
+		s.results[method] = result
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// SetResult makes the server answer method, which it does not implement
+// itself, with result, like Fixtures.Results.
+func (s *Server) SetResult(method string, result any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[method] = result
+}
+
+// Inject adds a fault. Faults are checked in the order they were added.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ExpireSessions ends every session, so the next call of each client fails
+// with CodeNotAuthenticated.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.sessions)
+}
+
+// Calls returns how often method was requested, including failed calls.
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+type rpcRequest struct {
+	ID     string          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	Jsonrpc string    `json:"jsonrpc"`
+	ID      string    `json:"id"`
+	Result  any       `json:"result,omitempty"`
+	Error   *rpcError `json:"error,omitempty"`
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls[req.Method]++
+	fault := s.takeFault(req.Method)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.Status != 0 {
+			w.WriteHeader(fault.Status)
+			return
+		}
+		if fault.Code != 0 {
+			writeResponse(w, rpcResponse{ID: req.ID, Error: &rpcError{fault.Code, "injected fault"}})
+			return
+		}
+	}
+
+	result, rpcErr := s.dispatch(w, r, req)
+	writeResponse(w, rpcResponse{ID: req.ID, Result: result, Error: rpcErr})
+}
+
+// takeFault returns the first fault matching method and uses it up. The
+// caller holds s.mu.
+func (s *Server) takeFault(method string) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) dispatch(w http.ResponseWriter, r *http.Request, req rpcRequest) (any, *rpcError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Method == "authenticate" {
+		return s.authenticate(w, req.Params)
+	}
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil || !s.sessions[cookie.Value] {
+		return nil, &rpcError{CodeNotAuthenticated, "not authenticated"}
+	}
+
+	switch req.Method {
+	case "logout":
+		delete(s.sessions, cookie.Value)
+		return nil, nil
+	case "getRooms":
+		return s.fixtures.Rooms, nil
+	case "getKlassen":
+		return s.fixtures.Classes, nil
+	case "getSubjects":
+		return s.fixtures.Subjects, nil
+	case "getTeachers":
+		return s.fixtures.Teachers, nil
+	case "getTimetable":
+		return s.timetable(req.Params)
+	}
+	if result, ok := s.results[req.Method]; ok {
+		return result, nil
+	}
+	return nil, &rpcError{CodeMethodNotFound, "method not found"}
+}
+
+func (s *Server) authenticate(w http.ResponseWriter, raw json.RawMessage) (any, *rpcError) {
+	var p untis.Params
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, &rpcError{CodeBadCredentials, "bad credentials"}
+	}
+	if p.Users != s.fixtures.User || p.Password != s.fixtures.Password {
+		return nil, &rpcError{CodeBadCredentials, "bad credentials"}
+	}
+
+	s.nextID++
+	session := "session-" + strconv.Itoa(s.nextID)
+	s.sessions[session] = true
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session})
+	return untis.Loginresult{
+		SessionID:  session,
+		PersonType: int(s.fixtures.PersonType),
+		PersonID:   s.fixtures.PersonID,
+		KlasseID:   s.fixtures.KlasseID,
+	}, nil
+}
+
+// timetable returns the lessons of the requested element between startDate
+// and endDate. A student sees every lesson.
+func (s *Server) timetable(raw json.RawMessage) (any, *rpcError) {
+	var p struct {
+		StartDate json.Number `json:"startDate"`
+		EndDate   json.Number `json:"endDate"`
+		ID        int         `json:"id"`
+		Type      int         `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, &rpcError{-32602, fmt.Sprintf("invalid params: %v", err)}
+	}
+	start, err1 := strconv.Atoi(p.StartDate.String())
+	end, err2 := strconv.Atoi(p.EndDate.String())
+	if err1 != nil || err2 != nil {
+		return nil, &rpcError{-32602, "invalid params: dates must be yyyymmdd"}
+	}
+
+	lessons := []Lesson{}
+	for _, l := range s.fixtures.Timetable {
+		if l.Date < start || l.Date > end {
+			continue
+		}
+		if untis.ElementType(p.Type) == untis.ElementStudent || l.has(untis.ElementType(p.Type), p.ID) {
+			lessons = append(lessons, l)
+		}
+	}
+	return lessons, nil
+}
+
+// has reports whether the lesson involves the element of type t with id.
+func (l Lesson) has(t untis.ElementType, id int) bool {
+	var objs []untis.IDObj
+	switch t {
+	case untis.ElementClass:
+		objs = l.Kl
+	case untis.ElementTeacher:
+		objs = l.Te
+	case untis.ElementSubject:
+		objs = l.Su
+	case untis.ElementRoom:
+		objs = l.Ro
+	}
+	for _, obj := range objs {
+		if obj.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func writeResponse(w http.ResponseWriter, resp rpcResponse) {
+	resp.Jsonrpc = "2.0"
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
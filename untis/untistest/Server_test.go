@@ -0,0 +1,148 @@
+package untistest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	untis "UntisTui/untis"
+	"UntisTui/untis/untistest"
+)
+
+func newServer(t *testing.T) *untistest.Server {
+	t.Helper()
+	srv := untistest.NewServer(untistest.DemoFixtures(time.Now()))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newClient(t *testing.T, srv *untistest.Server) *untis.Client {
+	t.Helper()
+	c, err := untis.NewClient(srv.URL, untis.HTTPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestMainWithStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	store, err := untis.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newClient(t, srv)
+	snap, err := untis.Main(ctx, c, store, "demo", "demo")
+	if err != nil {
+		t.Fatalf("Main = %v", err)
+	}
+	if len(snap.Rooms) != 2 || len(snap.Timegrid) != 3 || len(snap.Holidays) != 1 || snap.Schoolyear.Name == "" {
+		t.Errorf("incomplete snapshot: %+v", snap)
+	}
+	thursday := snap.Week[3]
+	if len(thursday) != 3 {
+		t.Fatalf("Thursday has %d lessons, want 3", len(thursday))
+	}
+	if got := thursday[0]; got.Ro[0] != "LAB" || got.RoOrg[0] != "R101" {
+		t.Errorf("Thursday's first lesson in %v instead of %v, want LAB instead of R101", got.Ro, got.RoOrg)
+	}
+	if err := c.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	cached, err := store.LoadSnapshot()
+	if err != nil {
+		t.Fatalf("LoadSnapshot = %v", err)
+	}
+	if cached.WeekStart != snap.WeekStart || len(cached.Week[3]) != 3 {
+		t.Errorf("cached snapshot differs: %+v", cached)
+	}
+
+	// nothing was imported since, so the second run uses the snapshot
+	again, err := untis.Main(ctx, newClient(t, srv), store, "demo", "demo")
+	if err != nil {
+		t.Fatalf("second Main = %v", err)
+	}
+	if n := srv.Calls("getTimetable"); n != 1 {
+		t.Errorf("getTimetable called %d times, want 1", n)
+	}
+	if len(again.Week[3]) != 3 {
+		t.Errorf("second run lost the week: %+v", again.Week)
+	}
+}
+
+func TestMainWithoutImportTime(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	srv.Inject(untistest.Fault{Method: "getLatestImportTime", Code: untistest.CodeNoRight})
+	store, err := untis.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := untis.Main(ctx, newClient(t, srv), store, "demo", "demo"); err != nil {
+		t.Fatalf("Main = %v", err)
+	}
+	cached, err := store.LoadSnapshot()
+	if err != nil {
+		t.Fatalf("LoadSnapshot = %v", err)
+	}
+	if cached.FetchedAt.IsZero() || cached.ImportTime != 0 {
+		t.Errorf("snapshot saved with FetchedAt %v, ImportTime %d, want set and unset", cached.FetchedAt, cached.ImportTime)
+	}
+
+	// without the import time the next run cannot tell whether the cache is
+	// current, so it fetches again
+	if _, err := untis.Main(ctx, newClient(t, srv), store, "demo", "demo"); err != nil {
+		t.Fatalf("second Main = %v", err)
+	}
+	if n := srv.Calls("getTimetable"); n != 2 {
+		t.Errorf("getTimetable called %d times, want 2", n)
+	}
+}
+
+func TestMainImportTimeError(t *testing.T) {
+	srv := newServer(t)
+	srv.Inject(untistest.Fault{Method: "getLatestImportTime", Code: -8998})
+	snap, err := untis.Main(context.Background(), newClient(t, srv), nil, "demo", "demo")
+	if err == nil {
+		t.Fatal("Main = nil, want the import time error")
+	}
+	if len(snap.Week[3]) != 3 {
+		t.Errorf("week not fetched: %+v", snap.Week)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	c := newClient(t, srv)
+	if err := c.Auth(ctx, "demo", "demo"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.ExpireSessions()
+	if _, err := c.Rooms(ctx); err != nil {
+		t.Fatalf("Rooms after expiry = %v", err)
+	}
+	if n := srv.Calls("authenticate"); n != 2 {
+		t.Errorf("authenticate called %d times, want 2", n)
+	}
+}
+
+func TestBadCredentials(t *testing.T) {
+	srv := newServer(t)
+	snap, err := untis.Main(context.Background(), newClient(t, srv), nil, "demo", "wrong")
+	if !errors.Is(err, untis.ErrBadCredentials) {
+		t.Fatalf("Main = %v, want ErrBadCredentials", err)
+	}
+	if snap != nil {
+		t.Errorf("Main returned a snapshot: %+v", snap)
+	}
+	if n := srv.Calls("getRooms"); n != 0 {
+		t.Errorf("getRooms called %d times without a login", n)
+	}
+}